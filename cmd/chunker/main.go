@@ -0,0 +1,76 @@
+package main
+
+import (
+	"DriveHack/internal/scraper"
+	"flag"
+	"log"
+)
+
+func main() {
+	// Параметры командной строки
+	input := flag.String("input", "data/sop_data.json", "Файл со страницами (результат скрапера или ingest)")
+	output := flag.String("output", "data/chunks.json", "Файл для сохранения чанков")
+	reportFile := flag.String("report", "", "Файл отчета о размерах чанков (пусто — только в лог)")
+	mode := flag.String("mode", scraper.ChunkSentence, "Режим разбиения: fixed, sentence, heading или tokens")
+	size := flag.Int("size", 0, "Размер чанка в символах, в режиме tokens — в токенах (0 — 1000 символов или 256 токенов)")
+	overlap := flag.Int("overlap", -1, "Перекрытие соседних чанков в тех же единицах (-1 — 100 символов или 32 токена)")
+	parentSize := flag.Int("parent-size", 0, "Максимальный размер родительского раздела в режиме heading (0 — 4 размера чанка)")
+	bp := scraper.RegisterBoilerplateFlags(flag.CommandLine, "")
+
+	flag.Parse()
+
+	opts := scraper.ChunkOptions{
+		Mode:       *mode,
+		Size:       *size,
+		Overlap:    *overlap,
+		ParentSize: *parentSize,
+	}
+	if err := opts.Validate(); err != nil {
+		log.Fatalf("Ошибка параметров чанков: %v", err)
+	}
+	if err := bp.Load(); err != nil {
+		log.Fatalf("Ошибка параметров шаблонного текста: %v", err)
+	}
+
+	log.Println("=== Нарезка страниц на чанки ===")
+
+	pages, err := scraper.LoadPages(*input)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки %s: %v", *input, err)
+	}
+	log.Printf("Загружено страниц: %d", len(pages))
+
+	// Строки, повторяющиеся на многих страницах (меню, подписки, подвал), в чанки не попадают
+	chunkPages, err := bp.Strip(pages)
+	if err != nil {
+		log.Fatalf("Ошибка сохранения отчета о шаблонном тексте: %v", err)
+	}
+
+	chunks := scraper.BuildChunks(chunkPages, opts)
+	if err := scraper.SaveChunkList(chunks, *output); err != nil {
+		log.Fatalf("Ошибка сохранения чанков: %v", err)
+	}
+
+	report := scraper.NewChunkReport(chunkPages, chunks, opts)
+	log.Printf("Режим: %s, размер: %d, перекрытие: %d", report.Mode, report.Size, report.Overlap)
+	log.Printf("Чанков: %d, родительских разделов: %d", report.Chunks, report.Sections)
+	log.Printf("Символы: мин %d, медиана %d, p90 %d, макс %d, среднее %.0f",
+		report.Chars.Min, report.Chars.Median, report.Chars.P90, report.Chars.Max, report.Chars.Mean)
+	log.Printf("Токены: мин %d, медиана %d, p90 %d, макс %d, среднее %.0f",
+		report.Tokens.Min, report.Tokens.Median, report.Tokens.P90, report.Tokens.Max, report.Tokens.Mean)
+	prev := 0
+	for _, bucket := range report.Histogram {
+		if bucket.UpTo == 0 {
+			log.Printf("  > %d: %d", prev, bucket.Count)
+			continue
+		}
+		log.Printf("  %d-%d: %d", prev+1, bucket.UpTo, bucket.Count)
+		prev = bucket.UpTo
+	}
+
+	if *reportFile != "" {
+		if err := scraper.SaveChunkReport(report, *reportFile); err != nil {
+			log.Fatalf("Ошибка сохранения отчета: %v", err)
+		}
+	}
+}
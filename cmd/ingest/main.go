@@ -0,0 +1,123 @@
+package main
+
+import (
+	"DriveHack/internal/scraper"
+	"flag"
+	"log"
+	"strings"
+)
+
+func main() {
+	// Параметры командной строки
+	dir := flag.String("dir", "", "Каталог с HTML, Markdown, TXT, PDF, DOCX и XLSX файлами")
+	baseURL := flag.String("base-url", "", "Префикс адресов страниц (по умолчанию file://)")
+	outputJSON := flag.String("output", "data/local_data.json", "Файл для сохранения данных")
+	chunksFile := flag.String("chunks", "data/local_chunks.json", "Файл для сохранения чанков")
+	programsFile := flag.String("programs", "data/local_programs.json", "Файл каталога программ обучения (пусто — не сохранять)")
+	entitiesFile := flag.String("entities", "data/local_entities.json", "Файл контактных сущностей (пусто — не сохранять)")
+	chunkSize := flag.Int("chunk-size", 0, "Размер чанка в символах, в режиме tokens — в токенах (0 — 1000 символов или 256 токенов)")
+	chunkOverlap := flag.Int("chunk-overlap", -1, "Перекрытие соседних чанков в тех же единицах (-1 — 100 символов или 32 токена)")
+	chunkMode := flag.String("chunk-mode", scraper.ChunkSentence, "Режим разбиения на чанки: fixed, sentence, heading (по заголовкам с родительскими разделами) или tokens")
+	parentSize := flag.Int("parent-size", 0, "Максимальный размер родительского раздела в режиме heading (0 — 4 размера чанка)")
+	mergeFile := flag.String("merge", "", "Добавить страницы из результата обхода сайта (например, data/sop_data.json)")
+	source := flag.String("source", "local", "Имя источника для поля source")
+	tags := flag.String("tags", "", "Теги страниц через запятую")
+	extractConfig := flag.String("extract-config", "", "JSON файл с CSS селекторами контента для HTML")
+	maxDocSize := flag.Int("max-doc-size", scraper.DefaultMaxDocumentSize/(1024*1024), "Максимальный размер файла (МБ)")
+	bp := scraper.RegisterBoilerplateFlags(flag.CommandLine, "data/local_boilerplate.json")
+
+	flag.Parse()
+
+	chunkOpts := scraper.ChunkOptions{
+		Mode:       *chunkMode,
+		Size:       *chunkSize,
+		Overlap:    *chunkOverlap,
+		ParentSize: *parentSize,
+	}
+	if err := chunkOpts.Validate(); err != nil {
+		log.Fatalf("Ошибка параметров чанков: %v", err)
+	}
+	if err := bp.Load(); err != nil {
+		log.Fatalf("Ошибка параметров шаблонного текста: %v", err)
+	}
+
+	if *dir == "" {
+		log.Fatal("Не указан каталог: -dir")
+	}
+
+	log.Println("=== Загрузка локальных файлов ===")
+	log.Printf("Каталог: %s", *dir)
+
+	ingester := scraper.NewLocalIngester(*dir)
+	ingester.BaseURL = *baseURL
+	ingester.Source = *source
+	ingester.MaxDocumentSize = *maxDocSize * 1024 * 1024
+	for _, tag := range strings.Split(*tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			ingester.Tags = append(ingester.Tags, tag)
+		}
+	}
+	if *extractConfig != "" {
+		extractor, err := scraper.LoadExtractorConfig(*extractConfig)
+		if err != nil {
+			log.Fatalf("Ошибка загрузки правил извлечения: %v", err)
+		}
+		ingester.Extractor = extractor
+	}
+
+	pages, err := ingester.Ingest()
+	if err != nil {
+		log.Fatalf("Ошибка загрузки файлов: %v", err)
+	}
+	log.Printf("Загружено файлов: %d", len(pages))
+
+	// Объединяем с результатом обхода, чтобы база знаний была одним файлом
+	if *mergeFile != "" {
+		crawled, err := scraper.LoadPages(*mergeFile)
+		if err != nil {
+			log.Fatalf("Ошибка загрузки %s: %v", *mergeFile, err)
+		}
+		local := make(map[string]bool, len(pages))
+		for _, page := range pages {
+			local[page.URL] = true
+		}
+		merged := make([]scraper.PageData, 0, len(crawled)+len(pages))
+		for _, page := range crawled {
+			if !local[page.URL] {
+				merged = append(merged, page)
+			}
+		}
+		pages = append(merged, pages...)
+		log.Printf("Добавлено страниц из %s: %d", *mergeFile, len(merged))
+	}
+
+	// Сохраняем данные и чанки в формате скрапера
+	log.Println("\nСохранение данных...")
+	if err := scraper.SavePages(pages, *outputJSON); err != nil {
+		log.Fatalf("Ошибка сохранения данных: %v", err)
+	}
+	// Строки, повторяющиеся на многих страницах (меню, подписки, подвал), в чанки не попадают
+	chunkPages, err := bp.Strip(pages)
+	if err != nil {
+		log.Fatalf("Ошибка сохранения отчета о шаблонном тексте: %v", err)
+	}
+	if err := scraper.SavePageChunks(chunkPages, *chunksFile, chunkOpts); err != nil {
+		log.Fatalf("Ошибка сохранения чанков: %v", err)
+	}
+	// Сохраняем контакты из загруженных файлов
+	if *entitiesFile != "" {
+		if err := scraper.SaveEntities(scraper.ExtractEntities(pages), *entitiesFile); err != nil {
+			log.Fatalf("Ошибка сохранения сущностей: %v", err)
+		}
+	}
+
+	// Сохраняем карточки программ обучения
+	if *programsFile != "" {
+		if err := scraper.SavePrograms(scraper.ExtractPrograms(pages), *programsFile); err != nil {
+			log.Fatalf("Ошибка сохранения каталога программ: %v", err)
+		}
+	}
+
+	log.Println("\n✓ Готово!")
+	log.Printf("Всего страниц: %d", len(pages))
+}
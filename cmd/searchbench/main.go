@@ -0,0 +1,145 @@
+package main
+
+import (
+	"DriveHack/internal/search"
+	"bufio"
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// benchQuery запрос и, если известен, адрес страницы с правильным ответом
+type benchQuery struct {
+	Text     string
+	Expected string
+}
+
+// loadQueries читает запросы: по одному на строку, через табуляцию — ожидаемый URL
+func loadQueries(filename string) ([]benchQuery, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var queries []benchQuery
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		text, expected, _ := strings.Cut(line, "\t")
+		queries = append(queries, benchQuery{Text: strings.TrimSpace(text), Expected: strings.TrimSpace(expected)})
+	}
+	return queries, scanner.Err()
+}
+
+func main() {
+	// Параметры командной строки
+	chunksFile := flag.String("chunks", "data/chunks.json", "Файл чанков базы знаний")
+	queriesFile := flag.String("queries", "", "Файл запросов: запрос[<TAB>ожидаемый URL] на строку")
+	topK := flag.Int("top", 5, "Сколько результатов учитывать")
+	repeat := flag.Int("repeat", 20, "Сколько раз повторять запросы для замера времени")
+	k1 := flag.Float64("k1", search.DefaultBM25K1, "Параметр k1 BM25")
+	b := flag.Float64("b", search.DefaultBM25B, "Параметр b BM25")
+	boostTitle := flag.Float64("boost-title", search.DefaultFieldBoosts.Title, "Вес заголовка страницы")
+	boostHeadings := flag.Float64("boost-headings", search.DefaultFieldBoosts.Headings, "Вес заголовков разделов и \"хлебных крошек\"")
+	boostBody := flag.Float64("boost-body", search.DefaultFieldBoosts.Body, "Вес текста")
+	boostURL := flag.Float64("boost-url", search.DefaultFieldBoosts.URL, "Вес пути URL")
+	boostAnchors := flag.Float64("boost-anchors", search.DefaultFieldBoosts.Anchors, "Вес текстов входящих ссылок")
+
+	flag.Parse()
+
+	if *queriesFile == "" {
+		log.Fatal("Не указан файл запросов: -queries")
+	}
+	queries, err := loadQueries(*queriesFile)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки запросов: %v", err)
+	}
+
+	kb := search.NewKnowledgeBase()
+	if err := kb.LoadChunks(*chunksFile); err != nil {
+		log.Fatalf("Ошибка загрузки чанков: %v", err)
+	}
+
+	bm25 := search.NewBM25()
+	bm25.K1, bm25.B = *k1, *b
+	bm25.Boosts = search.FieldBoosts{Title: *boostTitle, Headings: *boostHeadings, Body: *boostBody, URL: *boostURL, Anchors: *boostAnchors}
+	rankers := []struct {
+		name   string
+		ranker search.Ranker
+	}{
+		{search.RankerTFIDF, search.NewTFIDF()},
+		{search.RankerBM25, bm25},
+	}
+
+	log.Printf("=== Сравнение ранжирования: %d запросов, %d чанков ===", len(queries), len(kb.Chunks))
+	top := make([][][]int, len(rankers))
+	for r, rk := range rankers {
+		start := time.Now()
+		rk.ranker.BuildIndex(kb.Chunks)
+		buildTime := time.Since(start)
+
+		start = time.Now()
+		for i := 0; i < *repeat; i++ {
+			for _, q := range queries {
+				rk.ranker.Search(q.Text, *topK)
+			}
+		}
+		perQuery := time.Since(start) / time.Duration(max(*repeat*len(queries), 1))
+
+		// Качество по запросам с ожидаемым URL: доля найденных в топе и MRR
+		judged, hits := 0, 0
+		mrr := 0.0
+		for _, q := range queries {
+			results := rk.ranker.Search(q.Text, *topK)
+			ids := make([]int, len(results))
+			for i, res := range results {
+				ids[i] = res.Document.ID
+			}
+			top[r] = append(top[r], ids)
+
+			if q.Expected == "" {
+				continue
+			}
+			judged++
+			for i, res := range results {
+				if strings.HasPrefix(res.Document.URL, q.Expected) {
+					hits++
+					mrr += 1 / float64(i+1)
+					break
+				}
+			}
+		}
+
+		log.Printf("%s: индекс %v, запрос %v", rk.name, buildTime, perQuery)
+		if judged > 0 {
+			log.Printf("%s: hit@%d %.2f, MRR %.3f (%d запросов с ответом)", rk.name, *topK, float64(hits)/float64(judged), mrr/float64(judged), judged)
+		}
+	}
+
+	// Насколько совпадают выдачи: среднее пересечение топов
+	overlap := 0.0
+	for q := range queries {
+		seen := make(map[int]bool)
+		for _, id := range top[0][q] {
+			seen[id] = true
+		}
+		common := 0
+		for _, id := range top[1][q] {
+			if seen[id] {
+				common++
+			}
+		}
+		if n := max(len(top[0][q]), len(top[1][q])); n > 0 {
+			overlap += float64(common) / float64(n)
+		}
+	}
+	if len(queries) > 0 {
+		log.Printf("Совпадение топ-%d %s и %s: %.0f%%", *topK, rankers[0].name, rankers[1].name, 100*overlap/float64(len(queries)))
+	}
+}
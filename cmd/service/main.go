@@ -3,10 +3,12 @@ package main
 import (
 	gigaapi "DriveHack/internal/GigaChat"
 	"DriveHack/internal/salute"
+	"DriveHack/internal/search"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/gin-contrib/cors"
@@ -52,6 +54,48 @@ func handleChatRequest(c *gin.Context) {
 	c.JSON(http.StatusOK, ChatResponse{Response: resp})
 }
 
+// Обработчик для GET /api/programs: поиск и фильтр по каталогу программ.
+// Параметры: q, format, audience, min_hours, max_hours, max_price, free, limit
+func handleProgramsRequest(c *gin.Context) {
+	catalog := gigaapi.Programs()
+	if catalog == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Каталог программ не загружен"})
+		return
+	}
+
+	filter := search.ProgramFilter{
+		Query:    c.Query("q"),
+		Format:   c.Query("format"),
+		Audience: c.Query("audience"),
+		FreeOnly: c.Query("free") == "true",
+	}
+	for name, dst := range map[string]*int{
+		"min_hours": &filter.MinHours,
+		"max_hours": &filter.MaxHours,
+		"max_price": &filter.MaxPrice,
+		"limit":     &filter.Limit,
+	} {
+		if value := c.Query(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Некорректное значение %s", name)})
+				return
+			}
+			*dst = n
+		}
+	}
+
+	// total — число подходящих программ до ограничения limit
+	limit := filter.Limit
+	filter.Limit = 0
+	result := catalog.Filter(filter)
+	total := len(result)
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	c.JSON(http.StatusOK, gin.H{"programs": result, "total": total})
+}
+
 // Обработчик для POST /api/tts
 func handleTTSRequest(c *gin.Context) {
 	var reqData TTSRequest
@@ -182,6 +226,7 @@ func main() {
 	router.POST("/api/chat", handleChatRequest)
 	router.POST("/api/tts", handleTTSRequest)
 	router.POST("/api/stt", handleSTTRequest)
+	router.GET("/api/programs", handleProgramsRequest)
 
 	// Получение порта из переменных окружения
 	port := os.Getenv("SERVER_PORT")
@@ -2,6 +2,7 @@ package gigaapi
 
 import (
 	"DriveHack/internal/search"
+	"DriveHack/internal/tokens"
 	"context"
 	"log"
 	"os"
@@ -15,6 +16,9 @@ var (
 	model          *gigago.GenerativeModel
 	knowledgeBase  *search.KnowledgeBase
 	useKnowledge   bool
+	contextTokens  = search.DefaultContextTokens
+	entityStore    *search.EntityStore
+	programs       *search.ProgramCatalog
 )
 
 func InitClient() {
@@ -61,6 +65,8 @@ func InitClient() {
 
 ### Правило №1: Работа с предоставленным контекстом
 - Если в запросе есть раздел "Релевантная информация из базы знаний" - используй ТОЛЬКО эту информацию для ответа
+- Раздел "Каталог программ обучения" содержит точные карточки программ (формат, продолжительность, стоимость, даты) - для списков и сравнения программ используй его
+- Раздел "Контактные данные из базы знаний" содержит телефоны, email, адреса и режим работы с сайта - на вопросы о контактах отвечай по нему
 - НИКОГДА не придумывай факты, даты, контакты, программы или другую конкретную информацию
 - Если информации нет в предоставленном контексте - честно скажи об этом
 
@@ -95,7 +101,25 @@ func InitClient() {
 		knowledgeFile = "data/chunks.json"
 	}
 
+	// Бюджет контекста из базы знаний в токенах
+	if value := os.Getenv("CONTEXT_TOKENS"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			log.Printf("Предупреждение: некорректное значение CONTEXT_TOKENS, используется %d", search.DefaultContextTokens)
+		} else {
+			contextTokens = n
+		}
+	}
+
 	knowledgeBase = search.NewKnowledgeBase()
+	if name := os.Getenv("SEARCH_RANKER"); name != "" {
+		ranker, err := search.NewRanker(name)
+		if err != nil {
+			log.Printf("Предупреждение: %v, используется %s", err, search.RankerTFIDF)
+		} else {
+			knowledgeBase.SearchEngine = ranker
+		}
+	}
 	err = knowledgeBase.LoadChunks(knowledgeFile)
 	if err != nil {
 		log.Printf("База знаний не загружена (%s), работаем без контекста", knowledgeFile)
@@ -104,6 +128,33 @@ func InitClient() {
 		log.Println("База знаний успешно загружена")
 		useKnowledge = true
 	}
+
+	// Справочник контактов для вопросов вида "Как связаться..."
+	entitiesFile := os.Getenv("ENTITIES_FILE")
+	if entitiesFile == "" {
+		entitiesFile = "data/entities.json"
+	}
+
+	entityStore, err = search.LoadEntities(entitiesFile)
+	if err != nil {
+		log.Printf("Справочник контактов не загружен (%s): %v", entitiesFile, err)
+	}
+
+	// Каталог программ для вопросов о списке и сравнении программ
+	programsFile := os.Getenv("PROGRAMS_FILE")
+	if programsFile == "" {
+		programsFile = "data/programs.json"
+	}
+
+	programs, err = search.LoadPrograms(programsFile)
+	if err != nil {
+		log.Printf("Каталог программ не загружен (%s): %v", programsFile, err)
+	}
+}
+
+// Programs возвращает каталог программ обучения или nil, если он не загружен
+func Programs() *search.ProgramCatalog {
+	return programs
 }
 
 func CloseClient() {
@@ -113,18 +164,88 @@ func CloseClient() {
 	}
 }
 
-func GetResponse(userQuery string) string {
+// promptEntries сколько программ и контактов добавляется в запрос, если позволяет бюджет
+const promptEntries = 10
+
+// questionLabel отделяет контекст базы знаний от вопроса пользователя
+const questionLabel = "\n\nВопрос пользователя: "
+
+// fitEntries возвращает контекст из не более limit записей, который помещается
+// в budget токенов: лишние записи отбрасываются с конца, а если не помещается
+// даже одна, она обрезается
+func fitEntries(build func(limit int) string, limit, budget int) string {
+	if budget <= 0 {
+		return ""
+	}
+	for ; limit > 0; limit-- {
+		context := build(limit)
+		if tokens.Estimate(context) <= budget {
+			return context
+		}
+	}
+	return tokens.Truncate(build(1), budget)
+}
+
+// buildPrompt собирает запрос к модели. Каталог программ, контакты и чанки
+// базы знаний вместе укладываются в budget токенов (CONTEXT_TOKENS): каталог
+// и контакты собираются первыми, база знаний получает остаток. Пустой
+// источник (nil) не используется.
+func buildPrompt(userQuery string, budget int, catalogFor, contactsFor func(limit int) string, knowledgeFor func(maxTokens int) string) string {
+	// Карточки программ точнее чанков для вопросов "какие программы есть"
+	catalog := ""
+	if catalogFor != nil {
+		catalog = fitEntries(catalogFor, promptEntries, budget)
+		budget -= tokens.Estimate(catalog)
+	}
+
+	// Контакты добавляем отдельно: их не нужно искать по чанкам
+	contacts := ""
+	if contactsFor != nil {
+		contacts = fitEntries(contactsFor, promptEntries, budget)
+		budget -= tokens.Estimate(contacts)
+	}
+
 	// Формируем запрос с контекстом из базы знаний
 	finalQuery := userQuery
 
-	if useKnowledge {
-		context := knowledgeBase.GetContextForQuery(userQuery, 3)
-		if context != "" {
-			log.Println("Добавлен контекст из базы знаний")
-			finalQuery = context + "\n\nВопрос пользователя: " + userQuery
+	if knowledgeFor != nil {
+		budget -= tokens.Estimate(questionLabel)
+		if budget > 0 {
+			if context := knowledgeFor(budget); context != "" {
+				log.Println("Добавлен контекст из базы знаний")
+				finalQuery = context + questionLabel + userQuery
+			}
+		} else {
+			log.Println("Бюджет CONTEXT_TOKENS занят каталогом программ и контактами, контекст базы знаний не добавлен")
 		}
 	}
 
+	if catalog != "" {
+		log.Println("Добавлен каталог программ")
+		finalQuery = catalog + "\n" + finalQuery
+	}
+
+	if contacts != "" {
+		log.Println("Добавлены контактные данные")
+		finalQuery = contacts + "\n" + finalQuery
+	}
+	return finalQuery
+}
+
+func GetResponse(userQuery string) string {
+	var catalogFor, contactsFor func(int) string
+	var knowledgeFor func(int) string
+	if programs != nil {
+		catalogFor = func(limit int) string { return programs.GetContextForQuery(userQuery, limit) }
+	}
+	if entityStore != nil {
+		contactsFor = func(limit int) string { return entityStore.GetContextForQuery(userQuery, limit) }
+	}
+	if useKnowledge {
+		knowledgeFor = func(maxTokens int) string { return knowledgeBase.GetContextForQuery(userQuery, maxTokens) }
+	}
+	finalQuery := buildPrompt(userQuery, contextTokens, catalogFor, contactsFor, knowledgeFor)
+
 	// Отправляем запрос в GigaChat
 	ctx := context.Background()
 	messages := []gigago.Message{
@@ -0,0 +1,99 @@
+package gigaapi
+
+import (
+	"DriveHack/internal/tokens"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"testing"
+)
+
+func quietLog(tb testing.TB) {
+	tb.Helper()
+	out := log.Writer()
+	log.SetOutput(io.Discard)
+	tb.Cleanup(func() { log.SetOutput(out) })
+}
+
+// entriesFor имитирует каталог или справочник контактов: limit записей
+// с заголовком раздела
+func entriesFor(header, entry string, total int) func(limit int) string {
+	return func(limit int) string {
+		lines := []string{header}
+		for i := 0; i < min(limit, total); i++ {
+			lines = append(lines, fmt.Sprintf("%d. %s", i+1, entry))
+		}
+		return strings.Join(lines, "\n")
+	}
+}
+
+func TestBuildPromptWithinBudget(t *testing.T) {
+	quietLog(t)
+
+	entry := strings.Repeat("Повышение квалификации машинистов электропоезда метрополитена. ", 5)
+	catalogFor := entriesFor("Каталог программ обучения:", entry, 30)
+	contactsFor := entriesFor("Контактные данные из базы знаний:", "Приемная комиссия: +7 (495) 111-11-11, info@example.ru", 30)
+	knowledge := strings.Repeat("Обучение проходит в учебном центре на Шоссе Энтузиастов. ", 200)
+	var gotMaxTokens int
+	knowledgeFor := func(maxTokens int) string {
+		gotMaxTokens = maxTokens
+		return tokens.Truncate(knowledge, maxTokens)
+	}
+
+	query := "Какие программы есть для машинистов?"
+	for _, budget := range []int{0, 5, 20, 100, 500, 1500, 6000} {
+		gotMaxTokens = 0
+		prompt := buildPrompt(query, budget, catalogFor, contactsFor, knowledgeFor)
+		if got := tokens.Estimate(prompt) - tokens.Estimate(query); got > budget {
+			t.Errorf("budget %d: контекст занял %d токенов", budget, got)
+		}
+		if !strings.HasSuffix(prompt, query) {
+			t.Errorf("budget %d: запрос не заканчивается вопросом пользователя: %q", budget, prompt)
+		}
+		if gotMaxTokens < 0 {
+			t.Errorf("budget %d: база знаний получила отрицательный бюджет %d", budget, gotMaxTokens)
+		}
+	}
+
+	// Большой бюджет: все источники в запросе, база знаний получает остаток
+	prompt := buildPrompt(query, 6000, catalogFor, contactsFor, knowledgeFor)
+	for _, part := range []string{"Каталог программ обучения:", "Контактные данные из базы знаний:", "Вопрос пользователя: "} {
+		if !strings.Contains(prompt, part) {
+			t.Errorf("в запросе нет %q", part)
+		}
+	}
+	if strings.Contains(prompt, fmt.Sprintf("%d. ", promptEntries+1)) {
+		t.Errorf("в запросе больше %d записей", promptEntries)
+	}
+}
+
+func TestBuildPromptDropsKnowledge(t *testing.T) {
+	quietLog(t)
+
+	// Одна запись больше бюджета: она обрезается, на базу знаний места не остается
+	entry := strings.Repeat("Программа подготовки операторов пульта управления. ", 50)
+	catalogFor := entriesFor("Каталог программ обучения:", entry, 3)
+	called := false
+	knowledgeFor := func(maxTokens int) string {
+		called = true
+		return "Чанк базы знаний."
+	}
+
+	query := "Какие программы есть?"
+	prompt := buildPrompt(query, 40, catalogFor, nil, knowledgeFor)
+	if called {
+		t.Error("база знаний запрошена при исчерпанном бюджете")
+	}
+	if !strings.HasPrefix(prompt, "Каталог программ обучения:") || !strings.HasSuffix(prompt, "\n"+query) {
+		t.Errorf("запрос %q", prompt)
+	}
+	if got := tokens.Estimate(prompt) - tokens.Estimate(query); got > 40 {
+		t.Errorf("контекст занял %d токенов, бюджет 40", got)
+	}
+
+	// Без каталога и контактов весь бюджет достается базе знаний
+	if prompt := buildPrompt(query, 40, nil, nil, knowledgeFor); prompt != "Чанк базы знаний."+questionLabel+query {
+		t.Errorf("запрос %q", prompt)
+	}
+}
@@ -0,0 +1,32 @@
+// Package programs содержит общие для скрапера и поиска правила каталога
+// программ обучения: каталог собирает скрапер, а фильтрует поиск, и формат
+// обучения в обоих местах должен приводиться к одному словарю.
+package programs
+
+import "strings"
+
+// Форматы обучения
+const (
+	FormatOffline = "очно"
+	FormatOnline  = "онлайн"
+	FormatBlended = "смешанный"
+)
+
+// NormalizeFormat приводит формат обучения к одному из FormatOffline,
+// FormatOnline, FormatBlended; прочий текст возвращается без изменений
+func NormalizeFormat(value string) string {
+	value = strings.TrimSpace(value)
+	v := strings.ToLower(value)
+	online := strings.Contains(v, "онлайн") || strings.Contains(v, "дистанц") || strings.Contains(v, "online") || strings.Contains(v, "вебинар")
+	offline := strings.Contains(strings.ReplaceAll(v, "заочн", ""), "очн")
+	switch {
+	case strings.Contains(v, "смешан") || strings.Contains(v, "гибрид") || strings.Contains(v, "очно-заочн") || online && offline:
+		return FormatBlended
+	case online || strings.Contains(v, "заочн"):
+		// Заочное обучение в университете проходит дистанционно
+		return FormatOnline
+	case offline:
+		return FormatOffline
+	}
+	return value
+}
@@ -0,0 +1,25 @@
+package programs
+
+import "testing"
+
+func TestNormalizeFormat(t *testing.T) {
+	tests := map[string]string{
+		"Очно":                  FormatOffline,
+		"очная форма":           FormatOffline,
+		"Онлайн":                FormatOnline,
+		"дистанционно":          FormatOnline,
+		"вебинары":              FormatOnline,
+		"Заочная":               FormatOnline,
+		"Очно-заочная":          FormatBlended,
+		"смешанный":             FormatBlended,
+		"гибридный":             FormatBlended,
+		"очно или онлайн":       FormatBlended,
+		" по выбору слушателя ": "по выбору слушателя",
+		"":                      "",
+	}
+	for value, want := range tests {
+		if got := NormalizeFormat(value); got != want {
+			t.Errorf("NormalizeFormat(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package scraper
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultBoilerplateFraction доля страниц, на которых строка должна
+	// повторяться, чтобы считаться шаблонной (меню, подписки, подвал)
+	DefaultBoilerplateFraction = 0.3
+	// DefaultBoilerplateMinPages минимальное число страниц с повтором:
+	// на маленьком корпусе доля ничего не говорит
+	DefaultBoilerplateMinPages = 5
+)
+
+// BoilerplateOptions параметры удаления повторяющегося текста
+type BoilerplateOptions struct {
+	Fraction float64
+	MinPages int
+	// Allow регулярные выражения для строк, которые нужно сохранить, даже если они повторяются
+	Allow []*regexp.Regexp
+}
+
+// BoilerplateBlock повторяющаяся строка и число страниц, на которых она встретилась
+type BoilerplateBlock struct {
+	Text  string `json:"text"`
+	Pages int    `json:"pages"`
+}
+
+// BoilerplateReport отчет об удалении шаблонного текста
+type BoilerplateReport struct {
+	Pages        int                `json:"pages"`
+	Threshold    int                `json:"threshold"`
+	RemovedChars int                `json:"removed_chars"`
+	Removed      []BoilerplateBlock `json:"removed"`
+	// Kept повторяющиеся строки, сохраненные по списку разрешенных
+	Kept []BoilerplateBlock `json:"kept,omitempty"`
+}
+
+// boilerplateKey нормализует строку для сравнения: регистр, пробелы, маркер списка
+func boilerplateKey(line string) string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "- ")
+	return strings.ToLower(strings.Join(strings.Fields(line), " "))
+}
+
+// RemoveBoilerplate удаляет из текста и разделов страниц строки (абзацы, пункты
+// списков, строки таблиц), повторяющиеся на большой доле страниц корпуса.
+// Возвращает очищенные копии страниц; исходные страницы не изменяются.
+func RemoveBoilerplate(pages []PageData, opts BoilerplateOptions) ([]PageData, BoilerplateReport) {
+	if opts.Fraction <= 0 {
+		opts.Fraction = DefaultBoilerplateFraction
+	}
+	if opts.MinPages <= 0 {
+		opts.MinPages = DefaultBoilerplateMinPages
+	}
+	threshold := max(opts.MinPages, int(math.Ceil(opts.Fraction*float64(len(pages)))))
+	report := BoilerplateReport{Pages: len(pages), Threshold: threshold}
+
+	// На скольких страницах встречается строка и как она выглядит в тексте
+	counts := make(map[string]int)
+	samples := make(map[string]string)
+	for _, page := range pages {
+		seen := make(map[string]bool)
+		for _, line := range strings.Split(page.Text, "\n") {
+			key := boilerplateKey(line)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			counts[key]++
+			if _, ok := samples[key]; !ok {
+				samples[key] = strings.TrimSpace(line)
+			}
+		}
+	}
+
+	remove := make(map[string]bool)
+	for key, n := range counts {
+		if n < threshold {
+			continue
+		}
+		block := BoilerplateBlock{Text: samples[key], Pages: n}
+		if allowed(samples[key], opts.Allow) {
+			report.Kept = append(report.Kept, block)
+			continue
+		}
+		remove[key] = true
+		report.Removed = append(report.Removed, block)
+	}
+	for _, blocks := range [][]BoilerplateBlock{report.Removed, report.Kept} {
+		sort.Slice(blocks, func(i, j int) bool {
+			if blocks[i].Pages != blocks[j].Pages {
+				return blocks[i].Pages > blocks[j].Pages
+			}
+			return blocks[i].Text < blocks[j].Text
+		})
+	}
+
+	cleaned := make([]PageData, len(pages))
+	for i, page := range pages {
+		cleaned[i] = page
+		if len(remove) == 0 {
+			continue
+		}
+
+		var lines []string
+		for _, line := range strings.Split(page.Text, "\n") {
+			if remove[boilerplateKey(line)] {
+				report.RemovedChars += len([]rune(line))
+				continue
+			}
+			lines = append(lines, line)
+		}
+		cleaned[i].Text = strings.Join(lines, "\n")
+		cleaned[i].Length = len(cleaned[i].Text)
+		cleaned[i].Sections = cleanSections(page.Sections, remove)
+	}
+	return cleaned, report
+}
+
+// allowed проверяет строку по списку разрешенных выражений
+func allowed(text string, allow []*regexp.Regexp) bool {
+	for _, re := range allow {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanSections возвращает копию дерева разделов без шаблонных блоков
+func cleanSections(sections []Section, remove map[string]bool) []Section {
+	if sections == nil {
+		return nil
+	}
+	result := make([]Section, 0, len(sections))
+	for _, section := range sections {
+		var blocks []Block
+		for _, block := range section.Blocks {
+			switch block.Type {
+			case BlockList:
+				var items []string
+				for _, item := range block.Items {
+					if !remove[boilerplateKey(item)] {
+						items = append(items, item)
+					}
+				}
+				block.Items = items
+				if len(items) == 0 {
+					continue
+				}
+			case BlockTable:
+				var rows [][]string
+				for _, row := range block.Rows {
+					if !remove[boilerplateKey(strings.Join(row, " | "))] {
+						rows = append(rows, row)
+					}
+				}
+				block.Rows = rows
+				if len(rows) == 0 {
+					continue
+				}
+			default:
+				if remove[boilerplateKey(block.Text)] {
+					continue
+				}
+			}
+			blocks = append(blocks, block)
+		}
+		section.Blocks = blocks
+		section.Children = cleanSections(section.Children, remove)
+		if section.Heading == "" && len(section.Blocks) == 0 && len(section.Children) == 0 {
+			continue
+		}
+		result = append(result, section)
+	}
+	return result
+}
+
+// LoadBoilerplateAllowlist читает список разрешенных строк: по одному
+// регулярному выражению на строку (без учета регистра), # — комментарий
+func LoadBoilerplateAllowlist(filename string) ([]*regexp.Regexp, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+	defer file.Close()
+
+	var allow []*regexp.Regexp
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		re, err := regexp.Compile("(?i)" + line)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка в выражении %q: %w", line, err)
+		}
+		allow = append(allow, re)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+	return allow, nil
+}
+
+// SaveBoilerplateReport сохраняет отчет об удаленном шаблонном тексте в JSON файл
+func SaveBoilerplateReport(report BoilerplateReport, filename string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации: %w", err)
+	}
+
+	err = os.WriteFile(filename, data, 0644)
+	if err != nil {
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+
+	log.Printf("Отчет о шаблонном тексте сохранен в %s", filename)
+	return nil
+}
+
+// StripBoilerplate удаляет шаблонный текст перед нарезкой на чанки, пишет
+// итоги в лог и сохраняет отчет в reportFile (пусто — не сохранять)
+func StripBoilerplate(pages []PageData, opts BoilerplateOptions, reportFile string) ([]PageData, error) {
+	cleaned, report := RemoveBoilerplate(pages, opts)
+	log.Printf("Удалено шаблонных строк: %d (%d символов), сохранено по списку: %d", len(report.Removed), report.RemovedChars, len(report.Kept))
+	if reportFile != "" {
+		if err := SaveBoilerplateReport(report, reportFile); err != nil {
+			return nil, err
+		}
+	}
+	return cleaned, nil
+}
+
+// BoilerplateFlags общие флаги удаления шаблонного текста для команд,
+// которые режут страницы на чанки
+type BoilerplateFlags struct {
+	Fraction   float64
+	AllowFile  string
+	ReportFile string
+
+	allow []*regexp.Regexp
+}
+
+// RegisterBoilerplateFlags регистрирует флаги -boilerplate, -boilerplate-allow
+// и -boilerplate-report; defaultReport — файл отчета по умолчанию
+func RegisterBoilerplateFlags(fs *flag.FlagSet, defaultReport string) *BoilerplateFlags {
+	f := &BoilerplateFlags{}
+	fs.Float64Var(&f.Fraction, "boilerplate", DefaultBoilerplateFraction, "Перед нарезкой удалять строки, повторяющиеся на такой доле страниц (0 — не удалять)")
+	fs.StringVar(&f.AllowFile, "boilerplate-allow", "", "Файл с регулярными выражениями строк, которые нужно сохранить")
+	fs.StringVar(&f.ReportFile, "boilerplate-report", defaultReport, "Файл отчета об удаленном шаблонном тексте (пусто — не сохранять)")
+	return f
+}
+
+// Load загружает список разрешенных строк; вызывается после flag.Parse,
+// чтобы ошибка в файле обнаружилась до долгого обхода
+func (f *BoilerplateFlags) Load() error {
+	if f.AllowFile == "" {
+		return nil
+	}
+	allow, err := LoadBoilerplateAllowlist(f.AllowFile)
+	if err != nil {
+		return fmt.Errorf("ошибка загрузки списка разрешенных строк: %w", err)
+	}
+	f.allow = allow
+	return nil
+}
+
+// Strip удаляет шаблонный текст с параметрами из флагов; при -boilerplate 0
+// страницы возвращаются как есть
+func (f *BoilerplateFlags) Strip(pages []PageData) ([]PageData, error) {
+	if f.Fraction <= 0 {
+		return pages, nil
+	}
+	return StripBoilerplate(pages, BoilerplateOptions{Fraction: f.Fraction, Allow: f.allow}, f.ReportFile)
+}
@@ -0,0 +1,150 @@
+package scraper
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// boilerplatePages страницы с общим меню, подвалом и строкой таблицы;
+// n-я страница содержит свой абзац
+func boilerplatePages(n int) []PageData {
+	pages := make([]PageData, n)
+	for i := range pages {
+		own := fmt.Sprintf("Программа обучения номер %d.", i)
+		pages[i] = PageData{
+			URL: fmt.Sprintf("https://example.ru/page%d", i),
+			Text: strings.Join([]string{
+				"- Главная", "- Курсы", own, "Главная | Контакты", fmt.Sprintf("Курс %d | 72 часа", i),
+				"Подпишитесь на новости", "Телефон приемной: +7 (495) 123-45-67",
+			}, "\n"),
+			Sections: []Section{{
+				Blocks: []Block{
+					{Type: BlockList, Items: []string{"Главная", "Курсы"}},
+					{Type: BlockParagraph, Text: own},
+					{Type: BlockTable, Rows: [][]string{{"Главная", "Контакты"}, {fmt.Sprintf("Курс %d", i), "72 часа"}}},
+					{Type: BlockParagraph, Text: "Подпишитесь  на новости"},
+					{Type: BlockParagraph, Text: "Телефон приемной: +7 (495) 123-45-67"},
+				},
+			}},
+		}
+	}
+	return pages
+}
+
+func TestRemoveBoilerplate(t *testing.T) {
+	pages := boilerplatePages(6)
+	// Строка только с одной страницы не шаблонная
+	pages[0].Text += "\nТолько здесь"
+
+	opts := BoilerplateOptions{
+		Fraction: 0.5,
+		MinPages: 3,
+		Allow:    []*regexp.Regexp{regexp.MustCompile(`(?i)^телефон`)},
+	}
+	cleaned, report := RemoveBoilerplate(pages, opts)
+
+	if report.Threshold != 3 {
+		t.Errorf("Threshold = %d, want 3", report.Threshold)
+	}
+	var removed, kept []string
+	for _, block := range report.Removed {
+		removed = append(removed, block.Text)
+		if block.Pages != 6 {
+			t.Errorf("%q: страниц %d, want 6", block.Text, block.Pages)
+		}
+	}
+	for _, block := range report.Kept {
+		kept = append(kept, block.Text)
+	}
+	slices.Sort(removed)
+	if want := []string{"- Главная", "- Курсы", "Главная | Контакты", "Подпишитесь на новости"}; !slices.Equal(removed, want) {
+		t.Errorf("Removed = %q, want %q", removed, want)
+	}
+	if want := []string{"Телефон приемной: +7 (495) 123-45-67"}; !slices.Equal(kept, want) {
+		t.Errorf("Kept = %q, want %q", kept, want)
+	}
+
+	want := "Программа обучения номер 0.\nКурс 0 | 72 часа\nТелефон приемной: +7 (495) 123-45-67\nТолько здесь"
+	if cleaned[0].Text != want {
+		t.Errorf("текст %q, want %q", cleaned[0].Text, want)
+	}
+	if cleaned[0].Length != len(want) {
+		t.Errorf("Length = %d, want %d", cleaned[0].Length, len(want))
+	}
+
+	// В разделах: пункты меню, строка таблицы по ключу "ячейка | ячейка"
+	// и абзац с лишними пробелами удалены, разрешенный абзац сохранен
+	blocks := cleaned[1].Sections[0].Blocks
+	if len(blocks) != 3 {
+		t.Fatalf("блоков %d, want 3: %+v", len(blocks), blocks)
+	}
+	if blocks[0].Text != "Программа обучения номер 1." {
+		t.Errorf("первый блок %+v", blocks[0])
+	}
+	if rows := blocks[1].Rows; blocks[1].Type != BlockTable || len(rows) != 1 || rows[0][0] != "Курс 1" {
+		t.Errorf("таблица %+v, want одна строка с курсом", blocks[1])
+	}
+	if !strings.HasPrefix(blocks[2].Text, "Телефон") {
+		t.Errorf("последний блок %+v", blocks[2])
+	}
+
+	// Исходные страницы не изменились
+	if len(pages[1].Sections[0].Blocks) != 5 || !strings.Contains(pages[1].Text, "Подпишитесь") {
+		t.Error("RemoveBoilerplate изменил исходные страницы")
+	}
+}
+
+func TestRemoveBoilerplateMinPages(t *testing.T) {
+	// На маленьком корпусе доля не срабатывает: нужно MinPages страниц
+	cleaned, report := RemoveBoilerplate(boilerplatePages(3), BoilerplateOptions{})
+	if len(report.Removed) != 0 || cleaned[0].Text != boilerplatePages(3)[0].Text {
+		t.Errorf("на 3 страницах удалено %+v", report.Removed)
+	}
+}
+
+func TestBoilerplateFlags(t *testing.T) {
+	dir := t.TempDir()
+	allowFile := filepath.Join(dir, "allow.txt")
+	if err := os.WriteFile(allowFile, []byte("# контакты\n^телефон\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reportFile := filepath.Join(dir, "report.json")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	bp := RegisterBoilerplateFlags(fs, "")
+	if err := fs.Parse([]string{"-boilerplate", "0.5", "-boilerplate-allow", allowFile, "-boilerplate-report", reportFile}); err != nil {
+		t.Fatal(err)
+	}
+	if err := bp.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	pages := boilerplatePages(6)
+	cleaned, err := bp.Strip(pages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(cleaned[0].Text, "Подпишитесь") || !strings.Contains(cleaned[0].Text, "Телефон") {
+		t.Errorf("текст после очистки: %q", cleaned[0].Text)
+	}
+	if _, err := os.Stat(reportFile); err != nil {
+		t.Errorf("отчет не сохранен: %v", err)
+	}
+
+	// -boilerplate 0 отключает очистку
+	bp.Fraction = 0
+	if same, err := bp.Strip(pages); err != nil || same[0].Text != pages[0].Text {
+		t.Errorf("при -boilerplate 0 текст изменен: %q, %v", same[0].Text, err)
+	}
+
+	bp.AllowFile = filepath.Join(dir, "missing.txt")
+	if err := bp.Load(); err == nil {
+		t.Error("Load без файла: ошибки нет")
+	}
+}
@@ -0,0 +1,120 @@
+package scraper
+
+import (
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// trackingParams метки рекламы, аналитики и сессий, которые не влияют на содержимое
+// страницы. Общие имена вроде from, ref или print здесь не перечисляются:
+// на многих сайтах это настоящие параметры, и их удаление склеило бы разные страницы.
+var trackingParams = map[string]bool{
+	"gclid": true, "yclid": true, "fbclid": true, "ysclid": true,
+	"_openstat": true, "_ga": true, "mc_cid": true, "mc_eid": true,
+	"sessid": true, "phpsessid": true,
+}
+
+// NormalizeURL приводит URL к единому виду: схема и хост в нижнем регистре,
+// без порта по умолчанию, фрагмента, utm_* и прочих служебных параметров,
+// с отсортированными параметрами запроса. Если scheme не пустая, она
+// заменяет http/https, чтобы один и тот же сайт не обходился дважды.
+func NormalizeURL(raw, scheme string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return raw
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && !(port == "80" && u.Scheme == "http") && !(port == "443" && u.Scheme == "https") {
+		host += ":" + port
+	}
+	u.Host = host
+	if scheme != "" && (u.Scheme == "http" || u.Scheme == "https") {
+		u.Scheme = scheme
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.User = nil
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	query := u.Query()
+	for key := range query {
+		lower := strings.ToLower(key)
+		if strings.HasPrefix(lower, "utm_") || trackingParams[lower] {
+			query.Del(key)
+		}
+	}
+	u.RawQuery = encodeSorted(query)
+
+	return u.String()
+}
+
+// encodeSorted кодирует параметры запроса в стабильном порядке
+func encodeSorted(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, url.QueryEscape(k)+"="+url.QueryEscape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// urlKey ключ для сравнения URL: нормализованный URL без завершающего
+// слеша и index.html/index.php, так что /about, /about/ и /about/index.html совпадают
+func urlKey(raw, scheme string) string {
+	normalized := NormalizeURL(raw, scheme)
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return normalized
+	}
+
+	switch strings.ToLower(path.Base(u.Path)) {
+	case "index.html", "index.htm", "index.php":
+		u.Path = path.Dir(u.Path)
+	}
+	if len(u.Path) > 1 {
+		u.Path = strings.TrimRight(u.Path, "/")
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u.String()
+}
+
+// key возвращает ключ URL с учетом схемы базового URL скрапера
+func (s *Scraper) key(raw string) string {
+	return urlKey(raw, s.scheme)
+}
+
+// canonicalOf возвращает адрес из <link rel="canonical">, если он указывает на тот же сайт
+func (s *Scraper) canonicalOf(pageURL *url.URL, href string) string {
+	if href == "" {
+		return ""
+	}
+	ref, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return ""
+	}
+	abs := pageURL.ResolveReference(ref)
+	if abs.Hostname() != pageURL.Hostname() {
+		return ""
+	}
+	return NormalizeURL(abs.String(), s.scheme)
+}
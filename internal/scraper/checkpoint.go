@@ -0,0 +1,203 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// Checkpoint сохраненное состояние обхода для продолжения после прерывания
+type Checkpoint struct {
+	BaseURL   string     `json:"base_url"`
+	SavedAt   time.Time  `json:"saved_at"`
+	Frontier  []string   `json:"frontier"`
+	Visited   []string   `json:"visited"`
+	Unchanged []string   `json:"unchanged,omitempty"`
+	Pages     []PageData `json:"pages"`
+}
+
+// enqueue ставит ссылку в очередь обхода и запоминает ее во фронтире
+func (s *Scraper) enqueue(r *colly.Request, link string) {
+	abs := r.AbsoluteURL(link)
+	if abs == "" {
+		return
+	}
+
+	key := s.key(abs)
+	s.mu.Lock()
+	if !s.VisitedURLs[key] {
+		s.frontier[key] = abs
+	}
+	s.mu.Unlock()
+
+	r.Visit(abs)
+}
+
+// Stop останавливает обход: новые запросы отменяются, текущие завершаются
+func (s *Scraper) Stop() {
+	if s.stopped.CompareAndSwap(false, true) {
+		close(s.stopCh)
+
+		// Crawl не должен ждать таймеров отложенных повторов
+		s.mu.Lock()
+		s.retryCond.Broadcast()
+		s.mu.Unlock()
+	}
+}
+
+// Stopped сообщает, был ли обход остановлен до завершения
+func (s *Scraper) Stopped() bool {
+	return s.stopped.Load()
+}
+
+// snapshot копирует текущее состояние обхода
+func (s *Scraper) snapshot() Checkpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cp := Checkpoint{
+		BaseURL: s.BaseURL,
+		SavedAt: time.Now(),
+		Pages:   append([]PageData(nil), s.Pages...),
+	}
+
+	// Запросы "в полете" еще не дали страницу: после аварийного завершения
+	// их нужно загрузить заново, поэтому они идут во фронтир, а не в посещенные
+	inFlight := make(map[string]bool, len(s.inFlight))
+	frontier := make(map[string]string, len(s.frontier)+len(s.inFlight))
+	for _, u := range s.inFlight {
+		key := s.key(u)
+		inFlight[key] = true
+		frontier[key] = u
+	}
+	for key, u := range s.frontier {
+		if !s.VisitedURLs[key] {
+			frontier[key] = u
+		}
+	}
+	for _, seed := range s.SeedURLs {
+		if key := s.key(seed); !s.VisitedURLs[key] {
+			frontier[key] = seed
+		}
+	}
+	for _, u := range frontier {
+		cp.Frontier = append(cp.Frontier, u)
+	}
+	for u := range s.VisitedURLs {
+		if !inFlight[u] {
+			cp.Visited = append(cp.Visited, u)
+		}
+	}
+	for u := range s.unchanged {
+		cp.Unchanged = append(cp.Unchanged, u)
+	}
+	sort.Strings(cp.Frontier)
+	sort.Strings(cp.Visited)
+	sort.Strings(cp.Unchanged)
+
+	return cp
+}
+
+// SaveCheckpoint атомарно сохраняет состояние обхода в файл
+func (s *Scraper) SaveCheckpoint(filename string) error {
+	cp := s.snapshot()
+
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации: %w", err)
+	}
+
+	// Пишем во временный файл и переименовываем, чтобы не оставить битый чекпоинт
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("ошибка создания файла: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+
+	log.Printf("Чекпоинт сохранен в %s (страниц: %d, в очереди: %d)", filename, len(cp.Pages), len(cp.Frontier))
+	return nil
+}
+
+// LoadCheckpoint восстанавливает состояние обхода из файла.
+// Непосещенные URL из фронтира ставятся в начало очереди.
+func (s *Scraper) LoadCheckpoint(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return fmt.Errorf("ошибка парсинга JSON: %w", err)
+	}
+	if cp.BaseURL != s.BaseURL {
+		return fmt.Errorf("чекпоинт создан для %s, а не для %s", cp.BaseURL, s.BaseURL)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Pages = cp.Pages
+	for i, page := range s.Pages {
+		s.pageIndex[s.key(page.URL)] = i
+	}
+	for _, u := range cp.Visited {
+		s.VisitedURLs[u] = true
+	}
+	for _, u := range cp.Unchanged {
+		s.unchanged[u] = true
+	}
+	for _, u := range cp.Frontier {
+		s.frontier[s.key(u)] = u
+	}
+	s.SeedURLs = append(append([]string(nil), cp.Frontier...), s.SeedURLs...)
+
+	log.Printf("Обход продолжен с чекпоинта от %s: страниц %d, в очереди %d",
+		cp.SavedAt.Format(time.RFC3339), len(cp.Pages), len(cp.Frontier))
+	return nil
+}
+
+// StartCheckpoints периодически сохраняет чекпоинт, пока не вызвана функция остановки
+func (s *Scraper) StartCheckpoints(filename string, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.SaveCheckpoint(filename); err != nil {
+					log.Printf("Ошибка сохранения чекпоинта: %v", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+	}
+}
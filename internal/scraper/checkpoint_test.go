@@ -0,0 +1,64 @@
+package scraper
+
+import (
+	"net/url"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/gocolly/colly/v2"
+)
+
+func TestCheckpointKeepsInFlight(t *testing.T) {
+	const base = "https://example.com/"
+	request := func(id uint32, raw string) *colly.Request {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &colly.Request{URL: u, ID: id}
+	}
+
+	s := NewScraper(base, 10, 0)
+	done, running := request(1, base+"done"), request(2, base+"running")
+	for _, r := range []*colly.Request{done, running} {
+		if !s.reserve(r) {
+			t.Fatalf("reserve(%s) = false", r.URL)
+		}
+	}
+	s.addPage(done, PageData{URL: base + "done", Text: "текст"})
+	s.frontier[s.key(base+"queued")] = base + "queued"
+
+	cp := s.snapshot()
+	if !slices.Contains(cp.Frontier, base+"running") || !slices.Contains(cp.Frontier, base+"queued") {
+		t.Errorf("Frontier = %v: нет запроса в полете или ссылки из очереди", cp.Frontier)
+	}
+	if slices.Contains(cp.Visited, s.key(base+"running")) {
+		t.Errorf("Visited = %v: запрос в полете считается посещенным", cp.Visited)
+	}
+	if !slices.Contains(cp.Visited, s.key(base+"done")) {
+		t.Errorf("Visited = %v: нет загруженной страницы", cp.Visited)
+	}
+
+	// После аварийного завершения запрос в полете загружается заново
+	file := filepath.Join(t.TempDir(), "checkpoint.json")
+	if err := s.SaveCheckpoint(file); err != nil {
+		t.Fatal(err)
+	}
+	resumed := NewScraper(base, 10, 0)
+	if err := resumed.LoadCheckpoint(file); err != nil {
+		t.Fatal(err)
+	}
+	if len(resumed.Pages) != 1 || resumed.Pages[0].URL != base+"done" {
+		t.Errorf("Pages = %+v", resumed.Pages)
+	}
+	if !slices.Contains(resumed.SeedURLs, base+"running") {
+		t.Errorf("SeedURLs = %v: запрос в полете потерян", resumed.SeedURLs)
+	}
+	if !resumed.reserve(request(3, base+"running")) {
+		t.Error("запрос в полете после продолжения считается посещенным")
+	}
+	if resumed.reserve(request(4, base+"done")) {
+		t.Error("загруженная страница после продолжения запрашивается повторно")
+	}
+}
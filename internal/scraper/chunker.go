@@ -0,0 +1,429 @@
+package scraper
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"DriveHack/internal/tokens"
+)
+
+// Размеры чанков по умолчанию: в символах и в токенах для режима tokens
+const (
+	DefaultChunkSize    = 1000
+	DefaultChunkOverlap = 100
+	DefaultChunkTokens  = 256
+	DefaultTokenOverlap = 32
+)
+
+// Режимы разбиения страниц на чанки
+const (
+	// ChunkFixed режет текст на окна фиксированной длины без учета границ слов
+	ChunkFixed = "fixed"
+	// ChunkSentence делит текст страницы по границам предложений
+	ChunkSentence = "sentence"
+	// ChunkHeading делит страницу по дереву заголовков и сохраняет
+	// родительские разделы для выдачи в контекст целиком
+	ChunkHeading = "heading"
+	// ChunkTokens складывает целые предложения в чанки с бюджетом в токенах GigaChat
+	ChunkTokens = "tokens"
+)
+
+// ChunkKindSection тип чанка с полным текстом раздела: он не индексируется,
+// а подставляется в контекст вместо найденных дочерних чанков
+const ChunkKindSection = "section"
+
+// ChunkOptions параметры разбиения страниц на чанки. Size и Overlap задаются
+// в символах, в режиме tokens — в токенах; Size 0 и Overlap < 0 — значения по умолчанию.
+type ChunkOptions struct {
+	Mode    string
+	Size    int
+	Overlap int
+
+	// ParentSize максимальный размер родительского раздела в режиме heading;
+	// 0 — четыре размера чанка
+	ParentSize int
+}
+
+// Validate проверяет режим и размеры
+func (o ChunkOptions) Validate() error {
+	switch o.Mode {
+	case "", ChunkFixed, ChunkSentence, ChunkHeading, ChunkTokens:
+	default:
+		return fmt.Errorf("неизвестный режим разбиения на чанки: %s", o.Mode)
+	}
+	if o.Size < 0 {
+		return fmt.Errorf("размер чанка не может быть отрицательным: %d", o.Size)
+	}
+	return nil
+}
+
+// withDefaults подставляет значения по умолчанию
+func (o ChunkOptions) withDefaults() ChunkOptions {
+	if o.Mode == "" {
+		o.Mode = ChunkSentence
+	}
+	if o.Size <= 0 {
+		o.Size = DefaultChunkSize
+		if o.Mode == ChunkTokens {
+			o.Size = DefaultChunkTokens
+		}
+	}
+	if o.Overlap < 0 {
+		o.Overlap = DefaultChunkOverlap
+		if o.Mode == ChunkTokens {
+			o.Overlap = DefaultTokenOverlap
+		}
+	}
+	if o.ParentSize <= 0 {
+		o.ParentSize = 4 * o.Size
+	}
+	return o
+}
+
+// split делит текст в выбранном режиме
+func (o ChunkOptions) split(text string) []textSpan {
+	switch o.Mode {
+	case ChunkFixed:
+		return splitFixed(text, o.Size, o.Overlap)
+	case ChunkTokens:
+		return splitTokenChunks(text, o.Size, o.Overlap)
+	}
+	return splitChunks(text, o.Size, o.Overlap)
+}
+
+// BuildChunks разбивает страницы на чанки. В режиме heading страницы
+// без разделов (документы, старые данные) делятся по предложениям.
+// Каждый чанк получает стабильный ChunkID — хеш адреса, раздела и текста.
+func BuildChunks(pages []PageData, opts ChunkOptions) []Chunk {
+	opts = opts.withDefaults()
+
+	var chunks []Chunk
+	for _, page := range pages {
+		base := Chunk{
+			URL:    page.URL,
+			Title:  page.Title,
+			Source: page.Source,
+			Tags:   page.Tags,
+
+			Language:     page.Language,
+			Charset:      page.Charset,
+			Description:  page.Description,
+			OpenGraph:    page.OpenGraph,
+			Breadcrumbs:  page.Breadcrumbs,
+			LastModified: page.lastModified(),
+			FetchedAt:    page.FetchedAt,
+			Depth:        page.Depth,
+			Anchors:      page.Anchors,
+		}
+		// В страницах старых обходов языка нет, определяем по тексту
+		if base.Language == "" {
+			base.Language = detectLanguage(page.Text)
+		}
+
+		if opts.Mode == ChunkHeading && len(page.Sections) > 0 {
+			for _, section := range page.Sections {
+				chunks = appendSectionChunks(chunks, base, section, -1, opts)
+			}
+			continue
+		}
+		for _, span := range opts.split(page.Text) {
+			chunks = appendSpan(chunks, base, span)
+		}
+	}
+
+	assignChunkIDs(chunks)
+	return chunks
+}
+
+// assignChunkIDs вычисляет ChunkID по содержимому чанка. ID не меняется
+// при повторной нарезке, пока не изменились адрес, раздел и текст;
+// одинаковые чанки одной страницы различаются суффиксом.
+func assignChunkIDs(chunks []Chunk) {
+	seen := make(map[string]int)
+	for i := range chunks {
+		c := &chunks[i]
+		key := strings.Join([]string{c.URL, strings.Join(c.Section, "\x1f"), c.Kind, c.Text}, "\x00")
+		id := contentHash(key)[:16]
+		seen[id]++
+		if n := seen[id]; n > 1 {
+			id = fmt.Sprintf("%s-%d", id, n)
+		}
+		c.ChunkID = id
+	}
+}
+
+// appendSpan добавляет чанк с текстом фрагмента; ID — номер чанка в списке
+func appendSpan(chunks []Chunk, base Chunk, span textSpan) []Chunk {
+	chunk := base
+	chunk.ID = len(chunks)
+	chunk.Text = span.Text
+	chunk.StartPos = span.Start
+	chunk.EndPos = span.End
+	return append(chunks, chunk)
+}
+
+// appendSectionChunks добавляет чанки раздела и его подразделов. Родителем
+// становится самый верхний раздел, который целиком влезает в ParentSize;
+// parent — его ID или -1, если выше такого раздела нет. Раздел из одного
+// чанка без подразделов родителем не делается: он и так попадет в контекст целиком.
+// Позиции чанков считаются внутри текста раздела.
+func appendSectionChunks(chunks []Chunk, base Chunk, section Section, parent int, opts ChunkOptions) []Chunk {
+	base.Section = section.Path
+	own := sectionText(section)
+
+	if parent < 0 && (len(section.Children) > 0 || utf8.RuneCountInString(own) > opts.Size) {
+		if full := SectionsMarkdown([]Section{section}); utf8.RuneCountInString(full) <= opts.ParentSize {
+			parent = len(chunks)
+			chunks = appendSpan(chunks, base, textSpan{Text: full, End: utf8.RuneCountInString(full)})
+			chunks[parent].Kind = ChunkKindSection
+		}
+	}
+
+	child := base
+	if parent >= 0 {
+		id := parent
+		child.ParentID = &id
+	}
+	for _, span := range splitChunks(own, opts.Size, opts.Overlap) {
+		chunks = appendSpan(chunks, child, span)
+	}
+	for _, sub := range section.Children {
+		chunks = appendSectionChunks(chunks, base, sub, parent, opts)
+	}
+	return chunks
+}
+
+// sectionText собственный текст раздела без заголовка и подразделов
+func sectionText(section Section) string {
+	parts := make([]string, len(section.Blocks))
+	for i, block := range section.Blocks {
+		parts[i] = BlockMarkdown(block)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// textSpan фрагмент текста с позициями в символах (рунах)
+type textSpan struct {
+	Text  string
+	Start int
+	End   int
+}
+
+// splitChunks делит текст на чанки не длиннее size символов по границам
+// предложений. Предложение длиннее чанка делится по словам. Соседние чанки
+// перекрываются примерно на overlap символов, начиная с целого предложения
+// или слова. Позиции Start и End считаются в рунах.
+func splitChunks(text string, size, overlap int) []textSpan {
+	runes := []rune(text)
+	if size <= 0 || len(runes) == 0 {
+		return nil
+	}
+	if overlap < 0 {
+		overlap = 0
+	}
+	if overlap > size/2 {
+		overlap = size / 2
+	}
+
+	starts := tokens.SentenceStarts(runes)
+	var spans []textSpan
+	start := 0
+	for start < len(runes) {
+		end := start + size
+		if end >= len(runes) {
+			end = len(runes)
+		} else if b := lastBoundary(starts, start+size/2, end); b > 0 {
+			// Конец на границе предложения, если чанк не получается слишком коротким
+			end = b
+		} else if b := lastWordStart(runes, start+1, end); b > 0 {
+			end = b
+		}
+
+		span, ok := trimSpan(runes, start, end)
+		if ok {
+			spans = append(spans, span)
+			start = span.Start
+		}
+		if end == len(runes) {
+			break
+		}
+
+		// Следующий чанк начинается с предложения или слова внутри перекрытия
+		next := end
+		if overlap > 0 {
+			from := max(end-overlap, start+1)
+			if b := firstBoundary(starts, from, end); b > 0 {
+				next = b
+			} else if b := firstWordStart(runes, from, end); b > 0 {
+				next = b
+			}
+		}
+		start = next
+	}
+	return spans
+}
+
+// splitFixed режет текст на окна по size символов с шагом size-overlap
+func splitFixed(text string, size, overlap int) []textSpan {
+	runes := []rune(text)
+	if size <= 0 {
+		return nil
+	}
+	overlap = min(max(overlap, 0), size/2)
+
+	var spans []textSpan
+	for start := 0; start < len(runes); start += size - overlap {
+		end := min(start+size, len(runes))
+		if span, ok := trimSpan(runes, start, end); ok {
+			spans = append(spans, span)
+		}
+		if end == len(runes) {
+			break
+		}
+	}
+	return spans
+}
+
+// splitTokenChunks складывает в чанк целые предложения, пока он помещается
+// в budget токенов. Предложение больше бюджета делится по словам, слово — по
+// символам. Следующий чанк повторяет последние предложения предыдущего
+// общим размером не больше overlap токенов.
+func splitTokenChunks(text string, budget, overlap int) []textSpan {
+	runes := []rune(text)
+	if budget <= 0 || len(runes) == 0 {
+		return nil
+	}
+	overlap = min(max(overlap, 0), budget/2)
+
+	units := tokenUnits(runes, budget)
+	sizes := make([]int, len(units))
+	for i, u := range units {
+		sizes[i] = tokens.Estimate(string(runes[u.Start:u.End]))
+	}
+
+	var spans []textSpan
+	for i := 0; i < len(units); {
+		j, total := i, 0
+		for j < len(units) && (j == i || total+sizes[j] <= budget) {
+			total += sizes[j]
+			j++
+		}
+		if span, ok := trimSpan(runes, units[i].Start, units[j-1].End); ok {
+			spans = append(spans, span)
+		}
+		if j == len(units) {
+			break
+		}
+
+		next, carried := j, 0
+		for next-1 > i && carried+sizes[next-1] <= overlap {
+			next--
+			carried += sizes[next]
+		}
+		i = next
+	}
+	return spans
+}
+
+// tokenUnits делит текст на подряд идущие фрагменты не больше budget токенов:
+// предложения, а слишком длинные предложения — слова или куски слов
+func tokenUnits(runes []rune, budget int) []textSpan {
+	var units []textSpan
+	add := func(start, end int) {
+		// Фрагменты из одних пробелов не нужны: чанк с них не начинается
+		if strings.TrimSpace(string(runes[start:end])) != "" {
+			units = append(units, textSpan{Start: start, End: end})
+		}
+	}
+
+	starts := tokens.SentenceStarts(runes)
+	for k, start := range starts {
+		end := len(runes)
+		if k+1 < len(starts) {
+			end = starts[k+1]
+		}
+		if tokens.Estimate(string(runes[start:end])) <= budget {
+			add(start, end)
+			continue
+		}
+
+		// Длинное предложение: по словам
+		for from := start; from < end; {
+			to := firstWordStart(runes, from+1, end)
+			if to == 0 {
+				to = end
+			}
+			if tokens.Estimate(string(runes[from:to])) <= budget {
+				add(from, to)
+			} else {
+				for piece := from; piece < to; piece += tokens.MaxRunes(budget) {
+					add(piece, min(piece+tokens.MaxRunes(budget), to))
+				}
+			}
+			from = to
+		}
+	}
+	return units
+}
+
+// lastBoundary последняя граница предложения в (from, to]; 0, если ее нет
+func lastBoundary(starts []int, from, to int) int {
+	for i := len(starts) - 1; i >= 0; i-- {
+		if starts[i] <= to && starts[i] > from {
+			return starts[i]
+		}
+		if starts[i] <= from {
+			break
+		}
+	}
+	return 0
+}
+
+// firstBoundary первая граница предложения в [from, to); 0, если ее нет
+func firstBoundary(starts []int, from, to int) int {
+	for _, b := range starts {
+		if b >= from && b < to {
+			return b
+		}
+		if b >= to {
+			break
+		}
+	}
+	return 0
+}
+
+// lastWordStart позиция начала последнего слова в [from, to]; 0, если пробелов нет
+func lastWordStart(runes []rune, from, to int) int {
+	for i := to; i >= from && i > 0; i-- {
+		if i < len(runes) && !unicode.IsSpace(runes[i]) && unicode.IsSpace(runes[i-1]) {
+			return i
+		}
+	}
+	return 0
+}
+
+// firstWordStart позиция начала первого слова в [from, to); 0, если его нет
+func firstWordStart(runes []rune, from, to int) int {
+	for i := from; i < to; i++ {
+		if i > 0 && !unicode.IsSpace(runes[i]) && unicode.IsSpace(runes[i-1]) {
+			return i
+		}
+	}
+	return 0
+}
+
+// trimSpan убирает пробелы по краям фрагмента [start, end)
+func trimSpan(runes []rune, start, end int) (textSpan, bool) {
+	for start < end && unicode.IsSpace(runes[start]) {
+		start++
+	}
+	for end > start && unicode.IsSpace(runes[end-1]) {
+		end--
+	}
+	if start == end {
+		return textSpan{}, false
+	}
+	return textSpan{Text: string(runes[start:end]), Start: start, End: end}, true
+}
@@ -0,0 +1,237 @@
+package scraper
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"DriveHack/internal/search"
+	"DriveHack/internal/tokens"
+)
+
+func TestChunksKeepPageMetadata(t *testing.T) {
+	pages := []PageData{{
+		URL:       "https://example.ru/about",
+		Title:     "О центре",
+		Text:      "Учебный центр проводит курсы повышения квалификации для специалистов.",
+		Charset:   "windows-1251",
+		OpenGraph: map[string]string{"title": "О центре", "type": "website"},
+		Anchors:   []string{"О нас"},
+	}}
+
+	filename := filepath.Join(t.TempDir(), "chunks.json")
+	if err := SavePageChunks(pages, filename, ChunkOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	kb := search.NewKnowledgeBase()
+	if err := kb.LoadChunks(filename); err != nil {
+		t.Fatal(err)
+	}
+	if len(kb.Chunks) != 1 {
+		t.Fatalf("загружено %d чанков, want 1", len(kb.Chunks))
+	}
+	doc := kb.Chunks[0]
+	// Язык страниц старых обходов определяется по тексту
+	if doc.Language != "ru" || doc.Charset != "windows-1251" || doc.OpenGraph["type"] != "website" {
+		t.Errorf("метаданные документа: language %q, charset %q, og %v", doc.Language, doc.Charset, doc.OpenGraph)
+	}
+	if len(doc.Anchors) != 1 || doc.Anchors[0] != "О нас" {
+		t.Errorf("Anchors = %v", doc.Anchors)
+	}
+}
+
+// chunkText текст из повторяющихся предложений с многобайтной кириллицей
+func chunkText(n int) string {
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = fmt.Sprintf("Предложение номер %d о программах обучения, т.е. о курсах в г. Москва.", i+1)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestSplitModes(t *testing.T) {
+	text := chunkText(20)
+	runes := []rune(text)
+
+	tests := []struct {
+		mode    string
+		size    int
+		overlap int
+	}{
+		{ChunkSentence, 200, 0},
+		{ChunkSentence, 200, 60},
+		{ChunkSentence, 37, 10},
+		{ChunkFixed, 100, 0},
+		{ChunkFixed, 100, 30},
+		{ChunkTokens, 60, 0},
+		{ChunkTokens, 80, 30},
+		{ChunkTokens, 5, 2},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d/%d", tt.mode, tt.size, tt.overlap), func(t *testing.T) {
+			spans := ChunkOptions{Mode: tt.mode, Size: tt.size, Overlap: tt.overlap}.split(text)
+			if len(spans) < 2 {
+				t.Fatalf("чанков %d, want несколько", len(spans))
+			}
+			overlapped := 0
+			if spans[0].Start != 0 || spans[len(spans)-1].End != len(runes) {
+				t.Errorf("чанки покрывают [%d, %d), want [0, %d)", spans[0].Start, spans[len(spans)-1].End, len(runes))
+			}
+			for i, span := range spans {
+				// Позиции в рунах: текст чанка совпадает с вырезанным из исходного
+				if !utf8.ValidString(span.Text) || span.Text != string(runes[span.Start:span.End]) {
+					t.Fatalf("чанк %d [%d, %d) не совпадает с текстом: %q", i, span.Start, span.End, span.Text)
+				}
+				switch tt.mode {
+				case ChunkTokens:
+					if n := tokens.Estimate(span.Text); n > tt.size {
+						t.Errorf("чанк %d: %d токенов, бюджет %d", i, n, tt.size)
+					}
+				default:
+					if n := utf8.RuneCountInString(span.Text); n > tt.size {
+						t.Errorf("чанк %d: %d символов, размер %d", i, n, tt.size)
+					}
+				}
+				// Кроме фиксированных окон, чанк начинается с целого слова
+				if tt.mode != ChunkFixed && span.Start > 0 && !unicode.IsSpace(runes[span.Start-1]) {
+					t.Errorf("чанк %d начинается с середины слова: %q", i, span.Text)
+				}
+				if i == 0 {
+					continue
+				}
+				prev := spans[i-1]
+				if span.Start <= prev.Start {
+					t.Errorf("чанк %d не продвинулся: %d <= %d", i, span.Start, prev.Start)
+				}
+				if span.Start >= prev.End {
+					continue
+				}
+				// Перекрытие не больше overlap (в символах или токенах)
+				overlapped++
+				shared := string(runes[span.Start:prev.End])
+				size := utf8.RuneCountInString(shared)
+				if tt.mode == ChunkTokens {
+					size = tokens.Estimate(shared)
+				}
+				if size > tt.overlap {
+					t.Errorf("чанки %d и %d перекрываются на %d, overlap %d", i-1, i, size, tt.overlap)
+				}
+			}
+			if tt.overlap > 0 && overlapped == 0 {
+				t.Errorf("ни одна пара чанков не перекрывается при overlap %d", tt.overlap)
+			}
+		})
+	}
+}
+
+func TestSplitFixedCyrillic(t *testing.T) {
+	spans := splitFixed("абвгдеёжзи", 4, 1)
+	want := []textSpan{{"абвг", 0, 4}, {"гдеё", 3, 7}, {"ёжзи", 6, 10}}
+	if !slices.Equal(spans, want) {
+		t.Errorf("splitFixed = %v, want %v", spans, want)
+	}
+}
+
+func TestSplitChunksSentenceBoundaries(t *testing.T) {
+	text := "Курс проходит в г. Москва, ул. Тверская, т.е. в центре. Занятия по субботам. Запись открыта."
+	spans := splitChunks(text, 60, 0)
+	var got []string
+	for _, span := range spans {
+		got = append(got, span.Text)
+	}
+	want := []string{"Курс проходит в г. Москва, ул. Тверская, т.е. в центре.", "Занятия по субботам. Запись открыта."}
+	if !slices.Equal(got, want) {
+		t.Errorf("чанки %q, want %q", got, want)
+	}
+}
+
+// headingPage страница с разделом, подразделом и отдельным коротким разделом
+func headingPage(intro string) PageData {
+	para := func(text string) []Block { return []Block{{Type: BlockParagraph, Text: text}} }
+	return PageData{
+		URL:   "https://example.ru/programs",
+		Title: "Программы",
+		Text:  "текст",
+		Sections: []Section{
+			{Heading: "Обучение", Level: 1, Path: []string{"Обучение"}, Blocks: para(intro),
+				Children: []Section{
+					{Heading: "Курсы", Level: 2, Path: []string{"Обучение", "Курсы"},
+						Blocks: para("Курсы повышения квалификации. Программы переподготовки.")},
+				}},
+			{Heading: "Контакты", Level: 1, Path: []string{"Контакты"}, Blocks: para("Телефон приемной.")},
+		},
+	}
+}
+
+func TestBuildChunksHeading(t *testing.T) {
+	chunks := BuildChunks([]PageData{headingPage("Учебный центр ведет набор.")}, ChunkOptions{Mode: ChunkHeading, Size: 40})
+
+	type want struct {
+		kind    string
+		section string
+		parent  int // -1 — без родителя
+	}
+	wants := []want{
+		{ChunkKindSection, "Обучение", -1},
+		{"", "Обучение", 0},
+		{"", "Обучение > Курсы", 0},
+		{"", "Обучение > Курсы", 0},
+		{"", "Контакты", -1},
+	}
+	if len(chunks) != len(wants) {
+		t.Fatalf("чанков %d, want %d: %+v", len(chunks), len(wants), chunks)
+	}
+	for i, w := range wants {
+		c := chunks[i]
+		parent := -1
+		if c.ParentID != nil {
+			parent = *c.ParentID
+		}
+		if c.ID != i || c.Kind != w.kind || strings.Join(c.Section, " > ") != w.section || parent != w.parent {
+			t.Errorf("чанк %d: id %d, kind %q, section %v, parent %d; want %+v", i, c.ID, c.Kind, c.Section, parent, w)
+		}
+	}
+	if !strings.Contains(chunks[0].Text, "Курсы повышения квалификации") {
+		t.Errorf("родительский раздел без подраздела: %q", chunks[0].Text)
+	}
+}
+
+func TestAssignChunkIDsStable(t *testing.T) {
+	opts := ChunkOptions{Mode: ChunkHeading, Size: 40}
+	other := PageData{URL: "https://example.ru/about", Text: "О центре. О центре."}
+
+	first := BuildChunks([]PageData{headingPage("Учебный центр ведет набор."), other}, opts)
+	again := BuildChunks([]PageData{headingPage("Учебный центр ведет набор."), other}, opts)
+	changed := BuildChunks([]PageData{headingPage("Учебный центр ведет набор весной."), other}, opts)
+
+	ids := make(map[string]bool)
+	for i := range first {
+		if first[i].ChunkID != again[i].ChunkID {
+			t.Errorf("чанк %d: ID %s при повторной нарезке стал %s", i, first[i].ChunkID, again[i].ChunkID)
+		}
+		if ids[first[i].ChunkID] {
+			t.Errorf("повторяющийся ChunkID %s", first[i].ChunkID)
+		}
+		ids[first[i].ChunkID] = true
+	}
+
+	// Изменился только текст введения: ID раздела "Обучение" и его введения
+	// меняются, ID остальных чанков — нет
+	for i := range first {
+		same := first[i].ChunkID == changed[i].ChunkID
+		if wantSame := i >= 2; same != wantSame {
+			t.Errorf("чанк %d (%v): ID сохранился = %v, want %v", i, first[i].Section, same, wantSame)
+		}
+	}
+
+	// Одинаковый текст в пределах страницы различается суффиксом
+	dup := BuildChunks([]PageData{{URL: "https://example.ru/a", Text: "Повтор текста."}, {URL: "https://example.ru/a", Text: "Повтор текста."}}, ChunkOptions{})
+	if len(dup) != 2 || dup[1].ChunkID != dup[0].ChunkID+"-2" {
+		t.Errorf("ID одинаковых чанков: %v", []string{dup[0].ChunkID, dup[len(dup)-1].ChunkID})
+	}
+}
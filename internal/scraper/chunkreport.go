@@ -0,0 +1,123 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"unicode/utf8"
+
+	"DriveHack/internal/tokens"
+)
+
+// histogramBuckets число интервалов гистограммы до размера чанка
+const histogramBuckets = 10
+
+// SizeStats распределение размеров чанков
+type SizeStats struct {
+	Min    int     `json:"min"`
+	Max    int     `json:"max"`
+	Mean   float64 `json:"mean"`
+	Median int     `json:"median"`
+	P90    int     `json:"p90"`
+}
+
+// HistogramBucket число чанков с размером в (предыдущая граница, UpTo];
+// UpTo 0 у последнего интервала — больше размера чанка
+type HistogramBucket struct {
+	UpTo  int `json:"up_to"`
+	Count int `json:"count"`
+}
+
+// ChunkReport отчет о нарезке: сколько чанков и какого они размера.
+// Родительские разделы считаются отдельно и в распределение не входят.
+type ChunkReport struct {
+	Mode      string            `json:"mode"`
+	Size      int               `json:"size"`
+	Overlap   int               `json:"overlap"`
+	Pages     int               `json:"pages"`
+	Chunks    int               `json:"chunks"`
+	Sections  int               `json:"sections"`
+	Chars     SizeStats         `json:"chars"`
+	Tokens    SizeStats         `json:"tokens"`
+	Histogram []HistogramBucket `json:"histogram"`
+}
+
+// NewChunkReport считает распределение размеров чанков. Гистограмма строится
+// в единицах размера чанка: символах или токенах в режиме tokens.
+func NewChunkReport(pages []PageData, chunks []Chunk, opts ChunkOptions) ChunkReport {
+	opts = opts.withDefaults()
+	report := ChunkReport{Mode: opts.Mode, Size: opts.Size, Overlap: opts.Overlap, Pages: len(pages)}
+
+	var chars, toks []int
+	for _, chunk := range chunks {
+		if chunk.Kind == ChunkKindSection {
+			report.Sections++
+			continue
+		}
+		chars = append(chars, utf8.RuneCountInString(chunk.Text))
+		toks = append(toks, tokens.Estimate(chunk.Text))
+	}
+	report.Chunks = len(chars)
+	report.Chars = sizeStats(chars)
+	report.Tokens = sizeStats(toks)
+
+	sizes := chars
+	if opts.Mode == ChunkTokens {
+		sizes = toks
+	}
+	step := max(opts.Size/histogramBuckets, 1)
+	for upTo := step; upTo < opts.Size; upTo += step {
+		report.Histogram = append(report.Histogram, HistogramBucket{UpTo: upTo})
+	}
+	report.Histogram = append(report.Histogram, HistogramBucket{UpTo: opts.Size}, HistogramBucket{})
+	for _, size := range sizes {
+		i := 0
+		for i < len(report.Histogram)-1 && size > report.Histogram[i].UpTo {
+			i++
+		}
+		report.Histogram[i].Count++
+	}
+	return report
+}
+
+// sizeStats считает минимум, максимум, среднее и перцентили
+func sizeStats(sizes []int) SizeStats {
+	if len(sizes) == 0 {
+		return SizeStats{}
+	}
+	sorted := append([]int(nil), sizes...)
+	sort.Ints(sorted)
+
+	total := 0
+	for _, size := range sorted {
+		total += size
+	}
+	percentile := func(p float64) int {
+		return sorted[int(p*float64(len(sorted)-1))]
+	}
+	return SizeStats{
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+		Mean:   float64(total) / float64(len(sorted)),
+		Median: percentile(0.5),
+		P90:    percentile(0.9),
+	}
+}
+
+// SaveChunkReport сохраняет отчет о нарезке в JSON файл
+func SaveChunkReport(report ChunkReport, filename string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации: %w", err)
+	}
+
+	err = os.WriteFile(filename, data, 0644)
+	if err != nil {
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+
+	log.Printf("Отчет о нарезке сохранен в %s", filename)
+	return nil
+}
@@ -0,0 +1,103 @@
+package scraper
+
+import (
+	"log"
+	"net/http"
+	"regexp"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// limitedTransport ограничивает общее число одновременных HTTP запросов
+type limitedTransport struct {
+	base http.RoundTripper
+	sem  chan struct{}
+}
+
+// RoundTrip выполняет запрос, дожидаясь свободного воркера
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case t.sem <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	defer func() { <-t.sem }()
+	return t.base.RoundTrip(req)
+}
+
+// SetConcurrency включает параллельный обход.
+// workers — общее число одновременных запросов, perHost — к одному хосту.
+// При workers <= 1 обход остается последовательным.
+func (s *Scraper) SetConcurrency(workers, perHost int) {
+	if workers <= 1 {
+		s.Collector.Async = false
+		return
+	}
+	if perHost <= 0 || perHost > workers {
+		perHost = workers
+	}
+
+	s.Collector.Async = true
+	s.workers = workers
+	s.applyTransport()
+
+	// Ограничение на хост применяется к каждому разрешенному домену отдельно
+	s.limit.Parallelism = perHost
+
+	log.Printf("Параллельный обход: воркеров %d, на хост %d", workers, perHost)
+}
+
+// applyLimits регистрирует в Colly правило лимитов для каждого разрешенного
+// домена: у правила Colly одна очередь на все подходящие хосты, поэтому
+// общее правило "*" ограничивало бы параллельность всех доменов вместе.
+// Задержка и параллельность берутся из s.limit, оно же остается для прочих хостов.
+func (s *Scraper) applyLimits() {
+	rules := make([]*colly.LimitRule, 0, len(s.domains)+1)
+	for _, domain := range s.domains {
+		rules = append(rules, &colly.LimitRule{
+			// Colly сравнивает правило с хостом вместе с портом
+			DomainRegexp: `^` + regexp.QuoteMeta(domain) + `(:\d+)?$`,
+			Delay:        s.limit.Delay,
+			RandomDelay:  s.limit.RandomDelay,
+			Parallelism:  s.limit.Parallelism,
+		})
+	}
+	rules = append(rules, s.limit)
+	if err := s.Collector.Limits(rules); err != nil {
+		log.Printf("Ошибка настройки лимитов: %v", err)
+	}
+}
+
+// baseTransport транспорт без обвязки: архив WARC, пул прокси или прямое соединение
+func (s *Scraper) baseTransport() http.RoundTripper {
+	switch {
+	case s.replay != nil:
+		return s.replay
+	case s.proxies != nil:
+		return s.proxies
+	}
+	return http.DefaultTransport
+}
+
+// transport собирает транспорт HTTP с учетом настроек скрапера
+func (s *Scraper) transport() http.RoundTripper {
+	rt := s.baseTransport()
+	if s.workers > 1 {
+		rt = &limitedTransport{base: rt, sem: make(chan struct{}, s.workers)}
+	}
+	if s.warc != nil && s.replay == nil {
+		rt = &warcTransport{base: rt, writer: s.warc, maxBody: s.MaxDocumentSize}
+	}
+	return rt
+}
+
+// applyTransport обновляет транспорт Colly и клиента для robots.txt и sitemap
+func (s *Scraper) applyTransport() {
+	s.Collector.WithTransport(s.transport())
+
+	rt := s.baseTransport()
+	if s.warc != nil && s.replay == nil {
+		rt = &warcTransport{base: rt, writer: s.warc, maxBody: s.MaxDocumentSize}
+	}
+	s.HTTPClient.Transport = rt
+}
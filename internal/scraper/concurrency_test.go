@@ -0,0 +1,175 @@
+package scraper
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fixtureSite тестовый сайт: главная ссылается сначала на адреса, которые
+// не дают страниц (404, редирект на чужой домен, дубликаты по canonical),
+// а затем на обычные страницы, ссылающиеся друг на друга
+func fixtureSite(t *testing.T, pages int) *httptest.Server {
+	t.Helper()
+	page := func(w http.ResponseWriter, title, canonical string, links []string) {
+		var b strings.Builder
+		fmt.Fprintf(&b, "<html><head><title>%s</title>", title)
+		if canonical != "" {
+			fmt.Fprintf(&b, `<link rel="canonical" href="%s">`, canonical)
+		}
+		fmt.Fprintf(&b, "</head><body><main><h1>%s</h1><p>Текст страницы %s. Программа обучения для сотрудников метрополитена, расписание занятий и контакты учебного центра.</p>", title, title)
+		for _, link := range links {
+			fmt.Fprintf(&b, `<a href="%s">%s</a> `, link, link)
+		}
+		b.WriteString("</main></body></html>")
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(b.String()))
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Небольшая задержка, чтобы запросы действительно шли параллельно
+		time.Sleep(2 * time.Millisecond)
+
+		var n int
+		switch {
+		case r.URL.Path == "/":
+			var links []string
+			for i := 0; i < 5; i++ {
+				links = append(links, fmt.Sprintf("/missing%d", i), fmt.Sprintf("/away%d", i), fmt.Sprintf("/dup%d", i))
+			}
+			for i := 0; i < pages; i++ {
+				links = append(links, fmt.Sprintf("/page%d", i))
+			}
+			page(w, "Главная", "", links)
+		case strings.HasPrefix(r.URL.Path, "/away"):
+			http.Redirect(w, r, "http://elsewhere.invalid/", http.StatusFound)
+		case strings.HasPrefix(r.URL.Path, "/dup"):
+			page(w, "Дубликат", "/", nil)
+		case strings.HasPrefix(r.URL.Path, "/page"):
+			fmt.Sscanf(r.URL.Path, "/page%d", &n)
+			page(w, fmt.Sprintf("Страница %d", n), "", []string{
+				fmt.Sprintf("/page%d", (n+1)%pages),
+				fmt.Sprintf("/page%d", (n+7)%pages),
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestCrawlMaxPages(t *testing.T) {
+	srv := fixtureSite(t, 40)
+	defer srv.Close()
+
+	for _, workers := range []int{1, 8} {
+		for _, maxPages := range []int{1, 5, 12, 41} {
+			t.Run(fmt.Sprintf("workers=%d/max=%d", workers, maxPages), func(t *testing.T) {
+				s := NewScraper(srv.URL+"/", maxPages, 0)
+				s.MaxRetries = 0
+				s.RetryDelay = time.Millisecond
+				s.SetConcurrency(workers, workers)
+				s.Crawl(srv.URL + "/")
+
+				if len(s.Pages) != maxPages {
+					t.Errorf("собрано %d страниц, want %d", len(s.Pages), maxPages)
+				}
+				seen := make(map[string]bool)
+				for _, page := range s.Pages {
+					if seen[page.URL] {
+						t.Errorf("страница %s собрана дважды", page.URL)
+					}
+					seen[page.URL] = true
+				}
+			})
+		}
+	}
+}
+
+func TestCrawlWholeSiteNotLimited(t *testing.T) {
+	srv := fixtureSite(t, 10)
+	defer srv.Close()
+
+	s := NewScraper(srv.URL+"/", 100, 0)
+	s.MaxRetries = 0
+	s.RetryDelay = time.Millisecond
+	s.SetConcurrency(4, 4)
+	s.Crawl(srv.URL + "/")
+
+	// Главная и 10 страниц; 404, редиректы и дубликаты страниц не дают
+	if len(s.Pages) != 11 {
+		t.Errorf("собрано %d страниц, want 11", len(s.Pages))
+	}
+	if s.limited {
+		t.Error("обход всего сайта отмечен как урезанный лимитом")
+	}
+}
+
+// concurrencyProbe считает одновременные запросы к каждому хосту и в сумме
+type concurrencyProbe struct {
+	mu      sync.Mutex
+	active  map[string]int
+	maxHost map[string]int
+	total   int
+	maxAll  int
+}
+
+func (p *concurrencyProbe) enter(host string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.active[host]++
+	p.total++
+	p.maxHost[host] = max(p.maxHost[host], p.active[host])
+	p.maxAll = max(p.maxAll, p.total)
+}
+
+func (p *concurrencyProbe) leave(host string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.active[host]--
+	p.total--
+}
+
+func TestConcurrencyPerHost(t *testing.T) {
+	probe := &concurrencyProbe{active: map[string]int{}, maxHost: map[string]int{}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, _ := strings.Cut(r.Host, ":")
+		probe.enter(host)
+		defer probe.leave(host)
+		time.Sleep(20 * time.Millisecond)
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		var links string
+		if r.URL.Path == "/" {
+			// Страницы на двух хостах одного сервера
+			_, port, _ := strings.Cut(r.Host, ":")
+			for i := 0; i < 4; i++ {
+				links += fmt.Sprintf(`<a href="http://127.0.0.1:%s/a%d">a</a> <a href="http://localhost:%s/b%d">b</a> `, port, i, port, i)
+			}
+		}
+		fmt.Fprintf(w, "<html><body><p>Страница %s учебного центра.</p>%s</body></html>", r.URL.Path, links)
+	}))
+	defer srv.Close()
+
+	s := NewScraper(srv.URL+"/", 100, 0)
+	s.SetAllowedDomains([]string{"127.0.0.1", "localhost"})
+	s.RetryDelay = time.Millisecond
+	s.SetConcurrency(4, 1)
+	s.Crawl(srv.URL + "/")
+
+	if len(s.Pages) != 9 {
+		t.Fatalf("собрано %d страниц, want 9", len(s.Pages))
+	}
+	for host, n := range probe.maxHost {
+		if n > 1 {
+			t.Errorf("к %s одновременно %d запросов, want не больше 1", host, n)
+		}
+	}
+	// Ограничение на хост не ограничивает оба хоста вместе
+	if probe.maxAll < 2 {
+		t.Errorf("одновременно к двум хостам не больше %d запроса", probe.maxAll)
+	}
+}
@@ -0,0 +1,236 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// SourceConfig описание одного источника обхода
+type SourceConfig struct {
+	// Name имя источника, попадает в поле source страниц и чанков
+	Name string `json:"name"`
+	URL  string `json:"url"`
+
+	// AllowedDomains домены, по которым разрешено ходить (по умолчанию домен URL)
+	AllowedDomains []string `json:"allowed_domains,omitempty"`
+
+	// Include и Exclude регулярные выражения для URL: если Include задан,
+	// обходятся только подходящие адреса; подходящие под Exclude пропускаются
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+
+	MaxDepth int `json:"max_depth,omitempty"`
+	MaxPages int `json:"max_pages,omitempty"`
+	// Delay задержка между запросами в миллисекундах
+	Delay int `json:"delay,omitempty"`
+
+	ContentSelectors []string `json:"content_selectors,omitempty"`
+	RemoveSelectors  []string `json:"remove_selectors,omitempty"`
+	Sitemaps         []string `json:"sitemaps,omitempty"`
+	Tags             []string `json:"tags,omitempty"`
+}
+
+// CrawlConfig конфигурация обхода нескольких сайтов.
+// Значения из Defaults используются для незаданных полей источников.
+type CrawlConfig struct {
+	Defaults SourceConfig   `json:"defaults"`
+	Sources  []SourceConfig `json:"sources"`
+}
+
+// LoadCrawlConfig загружает конфигурацию обхода из YAML (.yaml, .yml) или JSON файла:
+//
+//	defaults: {max_pages: 200, delay: 1000}
+//	sources:
+//	  - name: sop
+//	    url: https://sop.mosmetro.ru/
+//	    exclude: ['\?sort=']
+//	    tags: [metro, education]
+func LoadCrawlConfig(filename string) (*CrawlConfig, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	var cfg CrawlConfig
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("ошибка парсинга YAML: %w", err)
+		}
+	default:
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("ошибка парсинга JSON: %w", err)
+		}
+	}
+
+	if len(cfg.Sources) == 0 {
+		return nil, fmt.Errorf("в конфигурации %s нет источников", filename)
+	}
+
+	names := make(map[string]bool, len(cfg.Sources))
+	for i := range cfg.Sources {
+		src := cfg.Defaults.apply(cfg.Sources[i])
+		if err := src.validate(); err != nil {
+			return nil, fmt.Errorf("источник %d: %w", i+1, err)
+		}
+		if names[src.Name] {
+			return nil, fmt.Errorf("источник %s указан несколько раз", src.Name)
+		}
+		names[src.Name] = true
+		cfg.Sources[i] = src
+	}
+
+	log.Printf("Загружена конфигурация обхода: %d источников", len(cfg.Sources))
+	return &cfg, nil
+}
+
+// apply дополняет источник значениями по умолчанию
+func (d SourceConfig) apply(src SourceConfig) SourceConfig {
+	if len(src.Include) == 0 {
+		src.Include = d.Include
+	}
+	if len(src.Exclude) == 0 {
+		src.Exclude = d.Exclude
+	}
+	if src.MaxDepth == 0 {
+		src.MaxDepth = d.MaxDepth
+	}
+	if src.MaxPages == 0 {
+		src.MaxPages = d.MaxPages
+	}
+	if src.Delay == 0 {
+		src.Delay = d.Delay
+	}
+	if len(src.ContentSelectors) == 0 {
+		src.ContentSelectors = d.ContentSelectors
+	}
+	if len(src.RemoveSelectors) == 0 {
+		src.RemoveSelectors = d.RemoveSelectors
+	}
+	src.Tags = append(append([]string(nil), d.Tags...), src.Tags...)
+
+	if src.Name == "" {
+		src.Name = extractDomain(src.URL)
+	}
+	if len(src.AllowedDomains) == 0 && src.URL != "" {
+		src.AllowedDomains = []string{extractDomain(src.URL)}
+	}
+	return src
+}
+
+// validate проверяет обязательные поля и регулярные выражения
+func (src SourceConfig) validate() error {
+	u, err := url.Parse(src.URL)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("некорректный URL %q", src.URL)
+	}
+	if src.MaxPages <= 0 {
+		return fmt.Errorf("%s: не задан max_pages", src.Name)
+	}
+	if _, err := compilePatterns(src.Include); err != nil {
+		return fmt.Errorf("%s: %w", src.Name, err)
+	}
+	if _, err := compilePatterns(src.Exclude); err != nil {
+		return fmt.Errorf("%s: %w", src.Name, err)
+	}
+	return nil
+}
+
+// compilePatterns компилирует список регулярных выражений
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var result []*regexp.Regexp
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("некорректное регулярное выражение %q: %w", p, err)
+		}
+		result = append(result, re)
+	}
+	return result, nil
+}
+
+// NewSourceScraper создает скрапер для источника из конфигурации
+func NewSourceScraper(src SourceConfig) (*Scraper, error) {
+	s := NewScraper(src.URL, src.MaxPages, time.Duration(src.Delay)*time.Millisecond)
+	s.Source = src.Name
+	s.Tags = src.Tags
+
+	if len(src.AllowedDomains) > 0 {
+		s.SetAllowedDomains(src.AllowedDomains)
+	}
+	if src.MaxDepth > 0 {
+		s.Collector.MaxDepth = src.MaxDepth
+	}
+	if err := s.SetURLFilters(src.Include, src.Exclude); err != nil {
+		return nil, err
+	}
+
+	if len(src.ContentSelectors) > 0 || len(src.RemoveSelectors) > 0 {
+		rules := SiteRules{Content: src.ContentSelectors, Remove: src.RemoveSelectors}
+		for _, domain := range s.domains {
+			s.Extractor.AddSiteRules(domain, rules)
+		}
+	}
+
+	return s, nil
+}
+
+// SetAllowedDomains задает домены, по которым разрешено ходить
+func (s *Scraper) SetAllowedDomains(domains []string) {
+	s.domains = domains
+	s.Collector.AllowedDomains = domains
+}
+
+// SetURLFilters задает регулярные выражения для отбора URL
+func (s *Scraper) SetURLFilters(include, exclude []string) error {
+	var err error
+	if s.include, err = compilePatterns(include); err != nil {
+		return err
+	}
+	if s.exclude, err = compilePatterns(exclude); err != nil {
+		return err
+	}
+	return nil
+}
+
+// allowedDomain проверяет, входит ли хост в разрешенные домены
+func (s *Scraper) allowedDomain(host string) bool {
+	for _, domain := range s.domains {
+		if host == domain {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedByFilters проверяет URL по include/exclude. Стартовый URL
+// разрешен всегда, иначе обход не смог бы начаться.
+func (s *Scraper) allowedByFilters(u *url.URL) bool {
+	raw := u.String()
+	if s.key(raw) == s.key(s.BaseURL) {
+		return true
+	}
+	for _, re := range s.exclude {
+		if re.MatchString(raw) {
+			return false
+		}
+	}
+	if len(s.include) == 0 {
+		return true
+	}
+	for _, re := range s.include {
+		if re.MatchString(raw) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package scraper
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeConfig записывает конфигурацию обхода во временный файл
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestLoadCrawlConfig(t *testing.T) {
+	yamlConfig := `defaults:
+  max_pages: 200
+  delay: 1000
+  exclude: ['\?sort=']
+  tags: [metro]
+sources:
+  - name: sop
+    url: https://sop.mosmetro.ru/
+    tags: [education]
+  - url: https://mosmetro.ru/
+    allowed_domains: [mosmetro.ru, www.mosmetro.ru]
+    max_pages: 50
+    exclude: ['/news/']
+`
+	jsonConfig := `{
+  "defaults": {"max_pages": 200, "delay": 1000, "exclude": ["\\?sort="], "tags": ["metro"]},
+  "sources": [
+    {"name": "sop", "url": "https://sop.mosmetro.ru/", "tags": ["education"]},
+    {"url": "https://mosmetro.ru/", "allowed_domains": ["mosmetro.ru", "www.mosmetro.ru"], "max_pages": 50, "exclude": ["/news/"]}
+  ]
+}`
+	for _, tt := range []struct{ name, content string }{
+		{"crawl.yaml", yamlConfig},
+		{"crawl.json", jsonConfig},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadCrawlConfig(writeConfig(t, tt.name, tt.content))
+			if err != nil {
+				t.Fatalf("LoadCrawlConfig: %v", err)
+			}
+			if len(cfg.Sources) != 2 {
+				t.Fatalf("источников %d, want 2", len(cfg.Sources))
+			}
+
+			sop, metro := cfg.Sources[0], cfg.Sources[1]
+			if sop.MaxPages != 200 || sop.Delay != 1000 || !slices.Equal(sop.Exclude, []string{`\?sort=`}) {
+				t.Errorf("значения по умолчанию не применены: %+v", sop)
+			}
+			if !slices.Equal(sop.Tags, []string{"metro", "education"}) {
+				t.Errorf("теги sop %v", sop.Tags)
+			}
+			if !slices.Equal(sop.AllowedDomains, []string{"sop.mosmetro.ru"}) {
+				t.Errorf("домены sop %v", sop.AllowedDomains)
+			}
+
+			// Имя по умолчанию — домен, заданные поля важнее значений по умолчанию
+			if metro.Name != "mosmetro.ru" || metro.MaxPages != 50 || !slices.Equal(metro.Exclude, []string{"/news/"}) {
+				t.Errorf("источник mosmetro %+v", metro)
+			}
+			if !slices.Equal(metro.AllowedDomains, []string{"mosmetro.ru", "www.mosmetro.ru"}) {
+				t.Errorf("домены mosmetro %v", metro.AllowedDomains)
+			}
+		})
+	}
+}
+
+func TestLoadCrawlConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{"без источников", "c.yaml", "defaults: {max_pages: 10}\n", "нет источников"},
+		{"некорректный URL", "c.yaml", "sources:\n  - url: sop.mosmetro.ru\n    max_pages: 10\n", "некорректный URL"},
+		{"без max_pages", "c.json", `{"sources": [{"url": "https://sop.mosmetro.ru/"}]}`, "не задан max_pages"},
+		{"регулярное выражение", "c.json", `{"defaults": {"max_pages": 10}, "sources": [{"url": "https://sop.mosmetro.ru/", "include": ["(["]}]}`, "регулярное выражение"},
+		{"повтор имени", "c.yaml", "defaults: {max_pages: 10}\nsources:\n  - url: https://sop.mosmetro.ru/\n  - url: https://sop.mosmetro.ru/about\n", "несколько раз"},
+		{"поврежденный YAML", "c.yml", "sources: [", "YAML"},
+		{"поврежденный JSON", "c.json", `{"sources": [`, "JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadCrawlConfig(writeConfig(t, tt.file, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ошибка %v, want содержащую %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := LoadCrawlConfig(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("отсутствующий файл загружен без ошибки")
+	}
+}
+
+func TestNewSourceScraper(t *testing.T) {
+	s, err := NewSourceScraper(SourceConfig{
+		Name:             "sop",
+		URL:              "https://sop.mosmetro.ru/",
+		AllowedDomains:   []string{"sop.mosmetro.ru", "mosmetro.ru"},
+		Include:          []string{`/courses/`},
+		Exclude:          []string{`\?print`},
+		MaxDepth:         3,
+		MaxPages:         20,
+		ContentSelectors: []string{".article"},
+		Tags:             []string{"education"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Source != "sop" || s.MaxPages != 20 || s.Collector.MaxDepth != 3 || !slices.Equal(s.Tags, []string{"education"}) {
+		t.Errorf("параметры источника не применены: source %q, max %d, depth %d, tags %v",
+			s.Source, s.MaxPages, s.Collector.MaxDepth, s.Tags)
+	}
+	if !s.allowedDomain("mosmetro.ru") || s.allowedDomain("example.com") {
+		t.Errorf("разрешенные домены %v", s.domains)
+	}
+	for _, domain := range []string{"sop.mosmetro.ru", "mosmetro.ru"} {
+		if rules := s.Extractor.rules(domain); !slices.Equal(rules.Content, []string{".article"}) {
+			t.Errorf("селекторы для %s: %v", domain, rules.Content)
+		}
+	}
+
+	for raw, want := range map[string]bool{
+		"https://sop.mosmetro.ru/":                true, // стартовый URL разрешен всегда
+		"https://sop.mosmetro.ru/courses/1":       true,
+		"https://sop.mosmetro.ru/courses/1?print": false,
+		"https://sop.mosmetro.ru/news/1":          false,
+	} {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := s.allowedByFilters(u); got != want {
+			t.Errorf("allowedByFilters(%s) = %v, want %v", raw, got, want)
+		}
+	}
+
+	if _, err := NewSourceScraper(SourceConfig{URL: "https://sop.mosmetro.ru/", MaxPages: 1, Exclude: []string{"("}}); err == nil {
+		t.Error("некорректный exclude принят")
+	}
+}
@@ -0,0 +1,281 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math/bits"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// DefaultNearDupDistance максимальное расстояние Хэмминга между SimHash
+	// страниц, при котором они считаются почти одинаковыми
+	DefaultNearDupDistance = 3
+	// shingleSize количество слов в шингле
+	shingleSize = 3
+	// simhashBands число полос для поиска кандидатов; при расстоянии
+	// меньше числа полос хотя бы одна полоса совпадает целиком
+	simhashBands = 4
+)
+
+// DuplicateGroup группа страниц, объединенных в одну
+type DuplicateGroup struct {
+	Kept       string   `json:"kept"`
+	Duplicates []string `json:"duplicates"`
+	Distance   int      `json:"distance"`
+}
+
+// DedupStats статистика дубликатов обхода
+type DedupStats struct {
+	// CanonicalDuplicates страницы, совпавшие по канонической ссылке
+	CanonicalDuplicates int `json:"canonical_duplicates"`
+	// NearDuplicates страницы, объединенные по SimHash
+	NearDuplicates int              `json:"near_duplicates"`
+	Groups         []DuplicateGroup `json:"groups,omitempty"`
+}
+
+// simhash вычисляет 64-битный SimHash по шинглам из слов текста
+func simhash(text string) uint64 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	add := func(shingle string) {
+		h := fnv.New64a()
+		h.Write([]byte(shingle))
+		sum := h.Sum64()
+		for i := 0; i < 64; i++ {
+			if sum&(1<<uint(i)) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+
+	if len(words) < shingleSize {
+		add(strings.Join(words, " "))
+	}
+	for i := 0; i+shingleSize <= len(words); i++ {
+		add(strings.Join(words[i:i+shingleSize], " "))
+	}
+
+	var result uint64
+	for i, w := range weights {
+		if w > 0 {
+			result |= 1 << uint(i)
+		}
+	}
+	return result
+}
+
+// ValidateNearDupDistance проверяет порог SimHash для Deduplicate.
+// Кандидаты ищутся по simhashBands полосам хеша, поэтому пары с расстоянием
+// simhashBands и больше могут не найтись: такой порог отклоняется.
+func ValidateNearDupDistance(maxDistance int) error {
+	if maxDistance >= simhashBands {
+		return fmt.Errorf("порог SimHash %d больше допустимого %d", maxDistance, simhashBands-1)
+	}
+	return nil
+}
+
+// Deduplicate объединяет почти одинаковые страницы (расстояние SimHash
+// не больше maxDistance). Остается страница с самым коротким URL, адреса
+// остальных записываются в ее Duplicates; каждая из них не дальше maxDistance
+// от оставленной, цепочки похожих страниц транзитивно не склеиваются.
+// Порог больше simhashBands-1 (см. ValidateNearDupDistance) уменьшается до него.
+// При maxDistance < 0 ничего не делает.
+func (s *Scraper) Deduplicate(maxDistance int) DedupStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := DedupStats{CanonicalDuplicates: s.canonicalDups}
+	if maxDistance < 0 || len(s.Pages) < 2 {
+		return stats
+	}
+	if err := ValidateNearDupDistance(maxDistance); err != nil {
+		log.Printf("%v, используется %d", err, simhashBands-1)
+		maxDistance = simhashBands - 1
+	}
+
+	hashes := make([]uint64, len(s.Pages))
+	for i, page := range s.Pages {
+		hashes[i] = simhash(page.Text)
+	}
+
+	drop := make(map[int]bool)
+	for _, members := range nearDuplicateGroups(s.Pages, hashes, maxDistance) {
+		kept := &s.Pages[members[0]]
+		group := DuplicateGroup{Kept: kept.URL}
+		for _, idx := range members[1:] {
+			dup := s.Pages[idx]
+			group.Distance = max(group.Distance, bits.OnesCount64(hashes[members[0]]^hashes[idx]))
+			kept.Duplicates = append(kept.Duplicates, dup.URL)
+			kept.Duplicates = append(kept.Duplicates, dup.Duplicates...)
+			kept.Anchors = mergeStrings(kept.Anchors, dup.Anchors)
+			group.Duplicates = append(group.Duplicates, dup.URL)
+			drop[idx] = true
+		}
+		stats.Groups = append(stats.Groups, group)
+		stats.NearDuplicates += len(members) - 1
+	}
+	sort.Slice(stats.Groups, func(i, j int) bool {
+		return stats.Groups[i].Kept < stats.Groups[j].Kept
+	})
+
+	pages := s.Pages[:0]
+	for i, page := range s.Pages {
+		if !drop[i] {
+			pages = append(pages, page)
+		}
+	}
+	s.Pages = pages
+
+	log.Printf("Дубликаты: по canonical %d, почти одинаковых %d", stats.CanonicalDuplicates, stats.NearDuplicates)
+	return stats
+}
+
+// nearDuplicateGroups возвращает группы индексов почти одинаковых страниц,
+// первой в группе идет оставляемая страница (с самым коротким URL).
+// Кандидаты находятся по совпадающим полосам SimHash и связываются
+// через union-find; связная компонента затем делится так, чтобы каждая
+// страница группы была не дальше maxDistance от оставляемой.
+func nearDuplicateGroups(pages []PageData, hashes []uint64, maxDistance int) [][]int {
+	parent := make([]int, len(pages))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	bandBits := 64 / simhashBands
+	for band := 0; band < simhashBands; band++ {
+		buckets := make(map[uint64][]int)
+		shift := uint(band * bandBits)
+		for i, h := range hashes {
+			if pages[i].Text == "" {
+				continue
+			}
+			bucket := (h >> shift) & (1<<uint(bandBits) - 1)
+			buckets[bucket] = append(buckets[bucket], i)
+		}
+		for _, members := range buckets {
+			for a := 0; a < len(members); a++ {
+				for b := a + 1; b < len(members); b++ {
+					i, j := members[a], members[b]
+					d := bits.OnesCount64(hashes[i] ^ hashes[j])
+					if d > maxDistance {
+						continue
+					}
+					if ri, rj := find(i), find(j); ri != rj {
+						parent[ri] = rj
+					}
+				}
+			}
+		}
+	}
+
+	components := make(map[int][]int)
+	var roots []int
+	for i := range pages {
+		root := find(i)
+		if _, ok := components[root]; !ok {
+			roots = append(roots, root)
+		}
+		components[root] = append(components[root], i)
+	}
+
+	var groups [][]int
+	for _, root := range roots {
+		rest := components[root]
+		if len(rest) < 2 {
+			continue
+		}
+		// Оставляем страницу с самым коротким URL
+		sort.Slice(rest, func(a, b int) bool {
+			ua, ub := pages[rest[a]].URL, pages[rest[b]].URL
+			if len(ua) != len(ub) {
+				return len(ua) < len(ub)
+			}
+			return ua < ub
+		})
+
+		// Цепочка A~B~C не делает дубликатами далекие друг от друга A и C:
+		// страницы дальше порога от оставляемой образуют следующую группу
+		for len(rest) > 1 {
+			kept := rest[0]
+			group := []int{kept}
+			var far []int
+			for _, idx := range rest[1:] {
+				if bits.OnesCount64(hashes[kept]^hashes[idx]) <= maxDistance {
+					group = append(group, idx)
+				} else {
+					far = append(far, idx)
+				}
+			}
+			if len(group) > 1 {
+				groups = append(groups, group)
+			}
+			rest = far
+		}
+	}
+	return groups
+}
+
+// mergeStrings объединяет отсортированные списки без повторов
+func mergeStrings(a, b []string) []string {
+	if len(b) == 0 {
+		return a
+	}
+	seen := make(map[string]bool, len(a)+len(b))
+	var result []string
+	for _, v := range append(append([]string(nil), a...), b...) {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// MergeDedupStats объединяет статистику дубликатов нескольких источников
+func MergeDedupStats(stats []DedupStats) DedupStats {
+	var merged DedupStats
+	for _, st := range stats {
+		merged.CanonicalDuplicates += st.CanonicalDuplicates
+		merged.NearDuplicates += st.NearDuplicates
+		merged.Groups = append(merged.Groups, st.Groups...)
+	}
+	return merged
+}
+
+// SaveDedupStats сохраняет статистику дубликатов в JSON файл
+func SaveDedupStats(stats DedupStats, filename string) error {
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации: %w", err)
+	}
+
+	err = os.WriteFile(filename, data, 0644)
+	if err != nil {
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+
+	log.Printf("Статистика дубликатов сохранена в %s", filename)
+	return nil
+}
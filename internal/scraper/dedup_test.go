@@ -0,0 +1,112 @@
+package scraper
+
+import (
+	"fmt"
+	"math/bits"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// dedupText длинный текст страницы; variant — последнее слово, например
+// дата обновления, которой отличаются почти одинаковые страницы
+func dedupText(topic, variant string) string {
+	var words []string
+	for i := 0; i < 300; i++ {
+		words = append(words, fmt.Sprintf("%s%d", topic, i))
+	}
+	return strings.Join(append(words, variant), " ")
+}
+
+func TestSimhash(t *testing.T) {
+	base := simhash(dedupText("обучение", "машинистов"))
+	near := simhash(dedupText("обучение", "диспетчеров"))
+	other := simhash(dedupText("контакты", "машинистов"))
+
+	if d := bits.OnesCount64(base ^ near); d > DefaultNearDupDistance {
+		t.Errorf("расстояние до почти одинаковой страницы %d, want <= %d", d, DefaultNearDupDistance)
+	}
+	if d := bits.OnesCount64(base ^ other); d <= 10 {
+		t.Errorf("расстояние до другой страницы %d, want > 10", d)
+	}
+	if simhash("Обучение, машинистов!") != simhash("обучение машинистов") {
+		t.Error("регистр и пунктуация влияют на SimHash")
+	}
+	if simhash(" , ") != 0 {
+		t.Error("SimHash текста без слов не равен 0")
+	}
+}
+
+func TestDeduplicate(t *testing.T) {
+	s := NewScraper("https://example.com/", 10, 0)
+	s.Pages = []PageData{
+		{URL: "https://example.com/courses?page=1", Text: dedupText("обучение", "диспетчеров"), Anchors: []string{"Курсы"}},
+		{URL: "https://example.com/courses", Text: dedupText("обучение", "машинистов")},
+		{URL: "https://example.com/contacts", Text: dedupText("контакты", "машинистов")},
+		{URL: "https://example.com/empty"},
+		{URL: "https://example.com/empty2"},
+	}
+
+	stats := s.Deduplicate(DefaultNearDupDistance)
+	if stats.NearDuplicates != 1 || len(stats.Groups) != 1 {
+		t.Fatalf("stats = %+v, want одну пару", stats)
+	}
+	group := stats.Groups[0]
+	if group.Kept != "https://example.com/courses" || !slices.Equal(group.Duplicates, []string{"https://example.com/courses?page=1"}) {
+		t.Errorf("группа %+v", group)
+	}
+	if group.Distance > DefaultNearDupDistance {
+		t.Errorf("Distance = %d больше порога", group.Distance)
+	}
+
+	// Страницы без текста не считаются дубликатами друг друга
+	if len(s.Pages) != 4 {
+		t.Fatalf("осталось %d страниц, want 4", len(s.Pages))
+	}
+	kept := s.Pages[0]
+	if kept.URL != "https://example.com/courses" || !slices.Equal(kept.Anchors, []string{"Курсы"}) ||
+		!slices.Equal(kept.Duplicates, []string{"https://example.com/courses?page=1"}) {
+		t.Errorf("оставленная страница %+v", kept)
+	}
+
+	// Отрицательный порог отключает объединение
+	s.Pages = append(s.Pages, PageData{URL: "https://example.com/copy", Text: kept.Text})
+	if stats := s.Deduplicate(-1); stats.NearDuplicates != 0 || len(s.Pages) != 5 {
+		t.Errorf("при пороге -1 объединено %d страниц", stats.NearDuplicates)
+	}
+}
+
+func TestNearDuplicateGroupsChain(t *testing.T) {
+	// A~B и B~C при пороге 3, но A и C отличаются на 4 бита; D рядом с C
+	pages := []PageData{
+		{URL: "/a", Text: "a"},
+		{URL: "/bb", Text: "b"},
+		{URL: "/ccc", Text: "c"},
+		{URL: "/dddd", Text: "d"},
+	}
+	hashes := []uint64{0b0, 0b11, 0b1111, 0b11111}
+
+	groups := nearDuplicateGroups(pages, hashes, 3)
+	want := [][]int{{0, 1}, {2, 3}}
+	if !slices.EqualFunc(groups, want, slices.Equal[[]int]) {
+		t.Fatalf("группы %v, want %v", groups, want)
+	}
+	for _, group := range groups {
+		for _, idx := range group[1:] {
+			if d := bits.OnesCount64(hashes[group[0]] ^ hashes[idx]); d > 3 {
+				t.Errorf("%s в группе %s на расстоянии %d", pages[idx].URL, pages[group[0]].URL, d)
+			}
+		}
+	}
+}
+
+func TestValidateNearDupDistance(t *testing.T) {
+	for _, d := range []int{-1, 0, DefaultNearDupDistance} {
+		if err := ValidateNearDupDistance(d); err != nil {
+			t.Errorf("порог %d: %v", d, err)
+		}
+	}
+	if err := ValidateNearDupDistance(simhashBands); err == nil {
+		t.Errorf("порог %d принят, хотя поиск по полосам его не гарантирует", simhashBands)
+	}
+}
@@ -0,0 +1,404 @@
+package scraper
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// Типы содержимого страниц и документов
+const (
+	ContentTypeHTML = "text/html"
+	ContentTypePDF  = "application/pdf"
+	ContentTypeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	ContentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+const (
+	// DefaultMaxDocumentSize ограничение размера скачиваемого документа
+	DefaultMaxDocumentSize = 20 * 1024 * 1024
+	// maxUnzippedPartSize защищает от zip-бомб в DOCX/XLSX
+	maxUnzippedPartSize = 100 * 1024 * 1024
+)
+
+// documentExtensions расширения документов, которые мы умеем разбирать
+var documentExtensions = map[string]string{
+	".pdf":  ContentTypePDF,
+	".docx": ContentTypeDOCX,
+	".xlsx": ContentTypeXLSX,
+}
+
+// documentType определяет тип документа по Content-Type или расширению URL.
+// Пустая строка означает, что это не документ.
+func documentType(u *url.URL, contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == ContentTypeHTML || mediaType == "application/xhtml+xml" {
+		return ""
+	}
+	for _, t := range documentExtensions {
+		if mediaType == t {
+			return t
+		}
+	}
+	return documentExtensions[strings.ToLower(path.Ext(u.Path))]
+}
+
+// documentTitle формирует заголовок документа по имени файла
+func documentTitle(u *url.URL) string {
+	name := path.Base(u.Path)
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// extractDocumentText извлекает текст из документа указанного типа
+func extractDocumentText(contentType string, data []byte) (text, title string, err error) {
+	switch contentType {
+	case ContentTypePDF:
+		return extractPDF(data)
+	case ContentTypeDOCX:
+		text, err = extractDOCX(data)
+		return text, "", err
+	case ContentTypeXLSX:
+		text, err = extractXLSX(data)
+		return text, "", err
+	}
+	return "", "", fmt.Errorf("неподдерживаемый тип документа: %s", contentType)
+}
+
+// extractPDF извлекает текст PDF постранично
+func extractPDF(data []byte) (text, title string, err error) {
+	// Библиотека может паниковать на поврежденных файлах
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("ошибка разбора PDF: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", "", fmt.Errorf("ошибка разбора PDF: %w", err)
+	}
+
+	var sb strings.Builder
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		pageText, err := page.GetPlainText(nil)
+		if err != nil {
+			return "", "", fmt.Errorf("ошибка чтения страницы %d: %w", i, err)
+		}
+		sb.WriteString(pageText)
+		sb.WriteString("\n")
+	}
+
+	title = strings.TrimSpace(reader.Trailer().Key("Info").Key("Title").Text())
+	return sb.String(), title, nil
+}
+
+// readZipPart читает файл из zip-архива с ограничением размера
+func readZipPart(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxUnzippedPartSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxUnzippedPartSize {
+		return nil, fmt.Errorf("%s превышает допустимый размер", f.Name)
+	}
+	return data, nil
+}
+
+// extractDOCX извлекает текст из word/document.xml, сохраняя абзацы
+func extractDOCX(data []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("ошибка разбора DOCX: %w", err)
+	}
+
+	for _, f := range zr.File {
+		if f.Name != "word/document.xml" {
+			continue
+		}
+		part, err := readZipPart(f)
+		if err != nil {
+			return "", fmt.Errorf("ошибка чтения DOCX: %w", err)
+		}
+		return docxText(part)
+	}
+	return "", fmt.Errorf("в DOCX нет word/document.xml")
+}
+
+// docxText обходит XML документа: w:t — текст, w:p — абзац, w:tab/w:br — разделители
+func docxText(part []byte) (string, error) {
+	var sb strings.Builder
+	dec := xml.NewDecoder(bytes.NewReader(part))
+	inText := false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("ошибка разбора XML: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				sb.WriteString("\t")
+			case "br", "cr":
+				sb.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				sb.WriteString("\n")
+			case "tc":
+				sb.WriteString("\t")
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+	return sb.String(), nil
+}
+
+// xlsxSheetRe находит листы книги и их номера
+var xlsxSheetRe = regexp.MustCompile(`^xl/worksheets/sheet(\d+)\.xml$`)
+
+// xlsxSheet лист книги: имя и файл с данными
+type xlsxSheet struct {
+	name string
+	file *zip.File
+}
+
+// extractXLSX извлекает текст всех листов: строка таблицы — строка текста
+func extractXLSX(data []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("ошибка разбора XLSX: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var shared []string
+	if f := files["xl/sharedStrings.xml"]; f != nil {
+		part, err := readZipPart(f)
+		if err != nil {
+			return "", fmt.Errorf("ошибка чтения XLSX: %w", err)
+		}
+		if shared, err = xlsxSharedStrings(part); err != nil {
+			return "", err
+		}
+	}
+
+	sheets, err := xlsxSheets(files)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for _, sh := range sheets {
+		part, err := readZipPart(sh.file)
+		if err != nil {
+			return "", fmt.Errorf("ошибка чтения XLSX: %w", err)
+		}
+		if sh.name != "" {
+			sb.WriteString(sh.name)
+			sb.WriteString("\n")
+		}
+		if err := xlsxSheetText(part, shared, &sb); err != nil {
+			return "", err
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+// xlsxSheets возвращает листы в порядке книги. Файл листа находится по r:id
+// через xl/_rels/workbook.xml.rels: номер в имени sheetN.xml не обязан
+// совпадать с позицией листа. Без workbook.xml листы идут по номерам без имен.
+func xlsxSheets(files map[string]*zip.File) ([]xlsxSheet, error) {
+	wb, rels := files["xl/workbook.xml"], files["xl/_rels/workbook.xml.rels"]
+	if wb != nil && rels != nil {
+		wbPart, err := readZipPart(wb)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения XLSX: %w", err)
+		}
+		relsPart, err := readZipPart(rels)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения XLSX: %w", err)
+		}
+		targets := xlsxRelTargets(relsPart)
+
+		var sheets []xlsxSheet
+		for _, ref := range xlsxSheetRefs(wbPart) {
+			if f := files[targets[ref.id]]; f != nil {
+				sheets = append(sheets, xlsxSheet{name: ref.name, file: f})
+			}
+		}
+		if len(sheets) > 0 {
+			return sheets, nil
+		}
+	}
+
+	type numbered struct {
+		num  int
+		file *zip.File
+	}
+	var found []numbered
+	for name, f := range files {
+		if m := xlsxSheetRe.FindStringSubmatch(name); m != nil {
+			num, _ := strconv.Atoi(m[1])
+			found = append(found, numbered{num: num, file: f})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].num < found[j].num })
+
+	sheets := make([]xlsxSheet, len(found))
+	for i, n := range found {
+		sheets[i] = xlsxSheet{file: n.file}
+	}
+	return sheets, nil
+}
+
+// xlsxSharedStrings читает таблицу общих строк
+func xlsxSharedStrings(part []byte) ([]string, error) {
+	var sst struct {
+		Items []struct {
+			T    string `xml:"t"`
+			Runs []struct {
+				T string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"si"`
+	}
+	if err := xml.Unmarshal(part, &sst); err != nil {
+		return nil, fmt.Errorf("ошибка разбора sharedStrings: %w", err)
+	}
+
+	result := make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		text := item.T
+		for _, run := range item.Runs {
+			text += run.T
+		}
+		result[i] = text
+	}
+	return result, nil
+}
+
+// xlsxSheetRef лист из workbook.xml: имя и r:id связи с файлом листа
+type xlsxSheetRef struct {
+	name string
+	id   string
+}
+
+// xlsxSheetRefs возвращает листы в порядке книги
+func xlsxSheetRefs(part []byte) []xlsxSheetRef {
+	var wb struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			// r:id из пространства имен relationships
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(part, &wb); err != nil {
+		return nil
+	}
+	refs := make([]xlsxSheetRef, len(wb.Sheets))
+	for i, s := range wb.Sheets {
+		refs[i] = xlsxSheetRef{name: s.Name, id: s.ID}
+	}
+	return refs
+}
+
+// xlsxRelTargets возвращает пути файлов в архиве по Id связи из workbook.xml.rels.
+// Target задается относительно xl/ или от корня архива, если начинается с "/".
+func xlsxRelTargets(part []byte) map[string]string {
+	var rels struct {
+		Items []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := xml.Unmarshal(part, &rels); err != nil {
+		return nil
+	}
+	targets := make(map[string]string, len(rels.Items))
+	for _, rel := range rels.Items {
+		if strings.HasPrefix(rel.Target, "/") {
+			targets[rel.ID] = strings.TrimPrefix(rel.Target, "/")
+		} else {
+			targets[rel.ID] = path.Join("xl", rel.Target)
+		}
+	}
+	return targets
+}
+
+// xlsxSheetText выводит строки листа, разделяя ячейки табуляцией
+func xlsxSheetText(part []byte, shared []string, sb *strings.Builder) error {
+	var ws struct {
+		Rows []struct {
+			Cells []struct {
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(part, &ws); err != nil {
+		return fmt.Errorf("ошибка разбора листа: %w", err)
+	}
+
+	for _, row := range ws.Rows {
+		var cells []string
+		for _, c := range row.Cells {
+			value := c.Value
+			switch c.Type {
+			case "s":
+				if idx, err := strconv.Atoi(c.Value); err == nil && idx >= 0 && idx < len(shared) {
+					value = shared[idx]
+				}
+			case "inlineStr":
+				value = c.Inline
+			}
+			if value = strings.TrimSpace(value); value != "" {
+				cells = append(cells, value)
+			}
+		}
+		if len(cells) > 0 {
+			sb.WriteString(strings.Join(cells, "\t"))
+			sb.WriteString("\n")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package scraper
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// zipFiles собирает zip-архив (DOCX/XLSX) из файлов в памяти
+func zipFiles(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func xlsxWorksheet(cells ...string) string {
+	xml := `<worksheet><sheetData><row>`
+	for _, c := range cells {
+		xml += `<c t="inlineStr"><is><t>` + c + `</t></is></c>`
+	}
+	return xml + `</row></sheetData></worksheet>`
+}
+
+func TestExtractXLSXSheetNames(t *testing.T) {
+	// Листы переставлены: первый в книге "Итоги" хранится в sheet2.xml
+	data := zipFiles(t, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+  xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+  <sheets>
+    <sheet name="Итоги" sheetId="2" r:id="rId7"/>
+    <sheet name="Расписание" sheetId="1" r:id="rId3"/>
+  </sheets>
+</workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+  <Relationship Id="rId7" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/sheet2.xml"/>
+</Relationships>`,
+		"xl/worksheets/sheet1.xml": xlsxWorksheet("Понедельник", "Охрана труда"),
+		"xl/worksheets/sheet2.xml": xlsxWorksheet("Всего часов", "72"),
+	})
+
+	text, err := extractXLSX(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Итоги\nВсего часов\t72\n\nРасписание\nПонедельник\tОхрана труда\n\n"
+	if text != want {
+		t.Errorf("extractXLSX = %q, want %q", text, want)
+	}
+}
+
+func TestExtractXLSXWithoutWorkbook(t *testing.T) {
+	data := zipFiles(t, map[string]string{
+		"xl/worksheets/sheet10.xml": xlsxWorksheet("десятый"),
+		"xl/worksheets/sheet2.xml":  xlsxWorksheet("второй"),
+	})
+	text, err := extractXLSX(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "второй\n\nдесятый\n\n"; text != want {
+		t.Errorf("extractXLSX = %q, want %q", text, want)
+	}
+}
+
+func TestDocumentTextKeepsLines(t *testing.T) {
+	docx := zipFiles(t, map[string]string{
+		"word/document.xml": `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>Порядок зачисления</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Подайте заявление.  </w:t></w:r><w:r><w:t>Приложите документы.</w:t></w:r></w:p>
+<w:p></w:p>
+<w:p><w:r><w:t>Контакты</w:t></w:r></w:p>
+</w:body></w:document>`,
+	})
+	raw, _, err := extractDocumentText(ContentTypeDOCX, docx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Порядок зачисления\nПодайте заявление.  Приложите документы.\nКонтакты"
+	if got := cleanText(raw); got != want {
+		t.Errorf("DOCX: %q, want %q", got, want)
+	}
+
+	// Локальный .txt тоже сохраняет строки
+	dir := t.TempDir()
+	txt := "  Заголовок\r\n\r\nПервая строка\n\tВторая строка  \n"
+	if err := os.WriteFile(filepath.Join(dir, "note.txt"), []byte(txt), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pages, err := NewLocalIngester(dir).Ingest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 1 {
+		t.Fatalf("страниц %d, want 1", len(pages))
+	}
+	if want := "Заголовок\nПервая строка\nВторая строка"; pages[0].Text != want {
+		t.Errorf("TXT: %q, want %q", pages[0].Text, want)
+	}
+}
+
+// pdfFile собирает минимальный PDF: по странице на каждую строку текста
+// (ASCII, шрифт Helvetica) и заголовок в словаре Info в UTF-16
+func pdfFile(title string, lines ...string) []byte {
+	var objects []string
+	add := func(obj string) int {
+		objects = append(objects, obj)
+		return len(objects)
+	}
+
+	catalog := add("")
+	pagesObj := add("")
+	font := add("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+	var utf16Title strings.Builder
+	utf16Title.WriteString("FEFF")
+	for _, c := range utf16.Encode([]rune(title)) {
+		fmt.Fprintf(&utf16Title, "%04X", c)
+	}
+	info := add(fmt.Sprintf("<< /Title <%s> >>", utf16Title.String()))
+
+	var kids []string
+	for _, line := range lines {
+		stream := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", line)
+		content := add(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream))
+		page := add(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+			pagesObj, font, content))
+		kids = append(kids, fmt.Sprintf("%d 0 R", page))
+	}
+	objects[catalog-1] = fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObj)
+	objects[pagesObj-1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(objects)+1, catalog, info, xref)
+	return buf.Bytes()
+}
+
+func TestExtractPDF(t *testing.T) {
+	data := pdfFile("Правила приема", "Admission rules", "Training schedule")
+	raw, title, err := extractDocumentText(ContentTypePDF, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if title != "Правила приема" {
+		t.Errorf("заголовок %q, want %q", title, "Правила приема")
+	}
+	if want := "Admission rules\nTraining schedule"; cleanText(raw) != want {
+		t.Errorf("PDF: %q, want %q", cleanText(raw), want)
+	}
+
+	// Поврежденный файл дает ошибку, а не панику
+	if _, _, err := extractDocumentText(ContentTypePDF, data[:len(data)/2]); err == nil {
+		t.Error("обрезанный PDF разобран без ошибки")
+	}
+}
+
+func TestExtractDOCXTablesAndBreaks(t *testing.T) {
+	docx := zipFiles(t, map[string]string{
+		"word/document.xml": `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>Адрес</w:t><w:br/><w:t>Москва</w:t></w:r></w:p>
+<w:tbl><w:tr>
+<w:tc><w:p><w:r><w:t>Курс</w:t></w:r></w:p></w:tc>
+<w:tc><w:p><w:r><w:t>Часы</w:t><w:tab/><w:t>72</w:t></w:r></w:p></w:tc>
+</w:tr></w:tbl>
+</w:body></w:document>`,
+	})
+	raw, _, err := extractDocumentText(ContentTypeDOCX, docx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Адрес\nМосква\nКурс\n\tЧасы\t72\n\t"
+	if raw != want {
+		t.Errorf("DOCX: %q, want %q", raw, want)
+	}
+
+	if _, err := extractDOCX(zipFiles(t, map[string]string{"word/other.xml": "<x/>"})); err == nil {
+		t.Error("DOCX без word/document.xml разобран без ошибки")
+	}
+}
+
+func TestCrawlDocuments(t *testing.T) {
+	docx := zipFiles(t, map[string]string{
+		"word/document.xml": `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>Учебный план на год</w:t></w:r></w:p>
+</w:body></w:document>`,
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, `<html><body><p>Документы учебного центра.</p>
+<a href="/files/rules.pdf">Правила приема</a>
+<a href="/files/schedule.pdf"><img src="/icon.png"></a>
+<a href="/files/plan.docx">Учебный план</a></body></html>`)
+		case "/files/rules.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write(pdfFile("", "Admission rules"))
+		case "/files/schedule.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write(pdfFile("Расписание занятий", "Training schedule"))
+		case "/files/plan.docx":
+			// Тип документа определяется по расширению
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write(docx)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	s := NewScraper(srv.URL+"/", 10, 0)
+	s.RetryDelay = time.Millisecond
+	s.Crawl(srv.URL + "/")
+
+	want := map[string]PageData{
+		// Текст ссылки важнее заголовка документа и имени файла
+		srv.URL + "/files/rules.pdf":    {Title: "Правила приема", Text: "Admission rules", ContentType: ContentTypePDF},
+		srv.URL + "/files/schedule.pdf": {Title: "Расписание занятий", Text: "Training schedule", ContentType: ContentTypePDF},
+		srv.URL + "/files/plan.docx":    {Title: "Учебный план", Text: "Учебный план на год", ContentType: ContentTypeDOCX},
+	}
+	found := 0
+	for _, page := range s.Pages {
+		w, ok := want[page.URL]
+		if !ok {
+			continue
+		}
+		found++
+		if page.Title != w.Title || page.Text != w.Text || page.ContentType != w.ContentType {
+			t.Errorf("%s: %q / %q / %s, want %q / %q / %s",
+				page.URL, page.Title, page.Text, page.ContentType, w.Title, w.Text, w.ContentType)
+		}
+		if page.Hash != contentHash(page.Text) || page.Length != len(page.Text) || page.Depth != 2 {
+			t.Errorf("%s: hash, длина или глубина не заполнены: %+v", page.URL, page)
+		}
+	}
+	if found != len(want) {
+		t.Errorf("собрано документов %d, want %d (%d страниц)", found, len(want), len(s.Pages))
+	}
+}
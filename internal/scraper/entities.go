@@ -0,0 +1,218 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Типы извлекаемых сущностей
+const (
+	EntityPhone      = "phone"
+	EntityEmail      = "email"
+	EntityAddress    = "address"
+	EntityHours      = "hours"
+	EntityDepartment = "department"
+)
+
+// maxEntityContext максимальная длина контекста сущности в символах
+const maxEntityContext = 300
+
+// Entity контактная сущность, найденная на страницах: телефон, email,
+// адрес, режим работы или подразделение
+type Entity struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+
+	// Department подразделение, к которому относится контакт (из той же строки или заголовка)
+	Department string `json:"department,omitempty"`
+
+	// Context строка страницы, в которой найдена сущность
+	Context string `json:"context,omitempty"`
+
+	Title string   `json:"title,omitempty"`
+	URLs  []string `json:"urls"`
+}
+
+var (
+	// Российские номера: +7 (495) 123-45-67, 8 800 123 45 67, +7-495-1234567, с добавочным
+	phoneRe = regexp.MustCompile(`(?:\+7|\b8)[\s\-]*\(?(\d{3})\)?[\s\-]*(\d{3})[\s\-]*(\d{2})[\s\-]*(\d{2})\b(?:[,\s]*(?:доб\.?|добавочный)\s*(\d{1,5}))?`)
+	emailRe = regexp.MustCompile(`(?i)\b[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}\b`)
+
+	// Адрес: [индекс,] [г. Москва,] улица с типом до или после названия, дом [, строение/корпус]
+	addressRe = regexp.MustCompile(`(?:\d{6},\s*)?(?:(?:г\.|город)\s*[А-ЯЁ][а-яё\-]+,\s*)?` +
+		`(?:(?:ул\.|улица|пр-т|проспект|просп\.|ш\.|шоссе|пер\.|переулок|б-р|бульвар|наб\.|набережная|пл\.|площадь|проезд|пр\.)\s*[А-ЯЁ0-9][^,;\n]{1,40}` +
+		`|[А-ЯЁ0-9][а-яёА-ЯЁ0-9\-]*(?:\s+[а-яёА-ЯЁ0-9\-]+){0,3}\s(?:ул\.|улица|пр-т|проспект|ш\.|шоссе|пер\.|переулок|б-р|бульвар|наб\.|набережная|пл\.|площадь|проезд))` +
+		`,\s*(?:д\.|дом)?\s*\d+[а-яА-Я]?(?:(?:,\s*|\s+)(?:стр\.|строение|корп\.|корпус|к\.)\s*\d+)*`)
+
+	// Режим работы: диапазон времени вместе с днями недели или словами "часы/режим работы"
+	timeRangeRe = regexp.MustCompile(`\d{1,2}[:.]\d{2}\s*(?:-|–|—|до)\s*\d{1,2}[:.]\d{2}`)
+	hoursHintRe = regexp.MustCompile(`(?i)(?:режим|часы|график|время)\s+работы|понедельник|пятниц|выходн|(?:^|[^а-яё])(?:пн|вт|ср|чт|пт|сб|вс)(?:[^а-яё]|$)`)
+
+	// Подразделение: ключевое слово с уточнением, например "Учебный центр" или "Отдел кадров"
+	departmentRe = regexp.MustCompile(`(?:[А-ЯЁ][а-яё\-]+\s+)?(?:[Оо]тдел|[Уу]правлени[ея]|[Дд]епартамент|[Цц]ентр|[Сс]лужба|[Дд]ирекция|[Кк]афедра|[Фф]акультет|[Сс]ектор|[Пп]риемная|[Пп]риёмная)(?:\s+[а-яёА-ЯЁ\-«»"]+){0,5}`)
+)
+
+// ExtractEntities находит контактные сущности на страницах. Одинаковые
+// сущности с разных страниц объединяются, адреса страниц собираются в URLs.
+func ExtractEntities(pages []PageData) []Entity {
+	index := make(map[string]int)
+	var entities []Entity
+
+	for _, page := range pages {
+		for _, e := range pageEntities(page) {
+			key := e.Type + "\x00" + strings.ToLower(e.Value)
+			if i, ok := index[key]; ok {
+				entities[i].URLs = mergeStrings(entities[i].URLs, e.URLs)
+				if entities[i].Department == "" {
+					entities[i].Department = e.Department
+				}
+				continue
+			}
+			index[key] = len(entities)
+			entities = append(entities, e)
+		}
+	}
+
+	sort.SliceStable(entities, func(i, j int) bool {
+		if entities[i].Type != entities[j].Type {
+			return entities[i].Type < entities[j].Type
+		}
+		return entities[i].Value < entities[j].Value
+	})
+	return entities
+}
+
+// pageEntities извлекает сущности из текста одной страницы построчно.
+// Подразделение запоминается из последней строки-заголовка или из строки с контактом.
+func pageEntities(page PageData) []Entity {
+	var entities []Entity
+	add := func(typ, value, department, line string) {
+		entities = append(entities, Entity{
+			Type:       typ,
+			Value:      value,
+			Department: department,
+			Context:    truncateRunes(line, maxEntityContext),
+			Title:      page.Title,
+			URLs:       []string{page.URL},
+		})
+	}
+
+	lines := strings.Split(page.Text, "\n")
+	department := ""
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		phones := phoneRe.FindAllStringSubmatch(line, -1)
+		emails := emailRe.FindAllString(line, -1)
+		contact := len(phones) > 0 || len(emails) > 0
+
+		// Подразделение в строке с контактом или короткая строка-заголовок перед контактами
+		lineDept := ""
+		if m := departmentRe.FindString(line); m != "" && startsUpper(m) {
+			m = strings.TrimSpace(strings.Trim(m, `«»" `))
+			switch {
+			case contact:
+				lineDept = m
+			case len([]rune(line)) <= 80 && nextHasContact(lines, i):
+				department = m
+			}
+		}
+		if lineDept != "" {
+			add(EntityDepartment, lineDept, "", line)
+		} else if department != "" && contact {
+			lineDept = department
+			add(EntityDepartment, department, "", line)
+		}
+
+		for _, m := range phones {
+			add(EntityPhone, formatPhone(m), lineDept, line)
+		}
+		for _, email := range emails {
+			add(EntityEmail, strings.ToLower(email), lineDept, line)
+		}
+		for _, addr := range addressRe.FindAllString(line, -1) {
+			add(EntityAddress, strings.Join(strings.Fields(addr), " "), lineDept, line)
+		}
+		if timeRangeRe.MatchString(line) && (hoursHintRe.MatchString(line) || hoursHeading(lines, i)) {
+			add(EntityHours, truncateRunes(line, maxEntityContext), lineDept, line)
+		}
+	}
+	return entities
+}
+
+// nextHasContact проверяет, есть ли телефон или email в ближайших строках после i
+func nextHasContact(lines []string, i int) bool {
+	for j := i + 1; j < len(lines) && j <= i+3; j++ {
+		if phoneRe.MatchString(lines[j]) || emailRe.MatchString(lines[j]) {
+			return true
+		}
+	}
+	return false
+}
+
+// hoursHeading проверяет, что строка i продолжает заголовок вида "Режим работы:"
+func hoursHeading(lines []string, i int) bool {
+	if i == 0 {
+		return false
+	}
+	prev := strings.TrimSpace(lines[i-1])
+	return len([]rune(prev)) <= 40 && hoursHintRe.MatchString(prev) && !timeRangeRe.MatchString(prev)
+}
+
+// formatPhone приводит номер к виду +7 (495) 123-45-67 [доб. 123]
+func formatPhone(m []string) string {
+	phone := fmt.Sprintf("+7 (%s) %s-%s-%s", m[1], m[2], m[3], m[4])
+	if m[5] != "" {
+		phone += " доб. " + m[5]
+	}
+	return phone
+}
+
+// startsUpper проверяет, что строка начинается с заглавной буквы
+func startsUpper(s string) bool {
+	for _, r := range s {
+		return r >= 'А' && r <= 'Я' || r == 'Ё'
+	}
+	return false
+}
+
+// truncateRunes обрезает строку до max символов
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:max])) + "…"
+}
+
+// SaveEntities сохраняет сущности в JSON файл
+func SaveEntities(entities []Entity, filename string) error {
+	if entities == nil {
+		entities = []Entity{}
+	}
+	data, err := json.MarshalIndent(entities, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации: %w", err)
+	}
+
+	err = os.WriteFile(filename, data, 0644)
+	if err != nil {
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+
+	counts := make(map[string]int)
+	for _, e := range entities {
+		counts[e.Type]++
+	}
+	log.Printf("Сохранено %d сущностей в %s (телефонов %d, email %d, адресов %d, режимов работы %d, подразделений %d)",
+		len(entities), filename, counts[EntityPhone], counts[EntityEmail], counts[EntityAddress], counts[EntityHours], counts[EntityDepartment])
+	return nil
+}
@@ -0,0 +1,69 @@
+package scraper
+
+import "testing"
+
+func TestPhoneRe(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Телефон: +7 (495) 123-45-67", "+7 (495) 123-45-67"},
+		{"Бесплатно по России 8 800 123 45 67", "+7 (800) 123-45-67"},
+		{"+7-495-1234567", "+7 (495) 123-45-67"},
+		{"+7 (495) 123-45-67, доб. 123", "+7 (495) 123-45-67 доб. 123"},
+		{"8 (495) 123-45-67 добавочный 45", "+7 (495) 123-45-67 доб. 45"},
+		{"+7(495)1234567", "+7 (495) 123-45-67"},
+		{"ИНН 7701234567", ""},
+		{"Номер договора 18001234567", ""},
+		{"8 800 123 45", ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if m := phoneRe.FindStringSubmatch(tt.text); m != nil {
+			got = formatPhone(m)
+		}
+		if got != tt.want {
+			t.Errorf("%q: телефон %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestAddressRe(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Адрес: 119991, г. Москва, ул. Тверская, д. 7", "119991, г. Москва, ул. Тверская, д. 7"},
+		{"г. Москва, улица Льва Толстого, дом 16", "г. Москва, улица Льва Толстого, дом 16"},
+		{"Мы находимся: Ленинский проспект, 32А", "Ленинский проспект, 32А"},
+		{"пр-т Мира, д. 15, стр. 2", "пр-т Мира, д. 15, стр. 2"},
+		{"Каширское шоссе, 31 корп. 2", "Каширское шоссе, 31 корп. 2"},
+		{"пер. Сивцев Вражек, 4", "пер. Сивцев Вражек, 4"},
+		{"Занятия проходят рядом с метро", ""},
+		{"ул. Тверская", ""},
+	}
+	for _, tt := range tests {
+		if got := addressRe.FindString(tt.text); got != tt.want {
+			t.Errorf("%q: адрес %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestExtractEntitiesMergesPages(t *testing.T) {
+	pages := []PageData{
+		{URL: "https://example.ru/a", Text: "Отдел кадров\nТелефон: +7 (495) 123-45-67"},
+		{URL: "https://example.ru/b", Text: "Звоните: 8 495 123 45 67"},
+	}
+	var phones []Entity
+	for _, e := range ExtractEntities(pages) {
+		if e.Type == EntityPhone {
+			phones = append(phones, e)
+		}
+	}
+	if len(phones) != 1 {
+		t.Fatalf("телефоны %+v, want один объединенный", phones)
+	}
+	if phones[0].Department != "Отдел кадров" || len(phones[0].URLs) != 2 {
+		t.Errorf("телефон %+v", phones[0])
+	}
+}
@@ -0,0 +1,421 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// noiseSelector элементы, которые никогда не бывают основным контентом
+const noiseSelector = "script, style, noscript, iframe, svg, template, nav, footer, header, aside, button, select, input, textarea"
+
+var (
+	// unlikelyRe классы и id служебных блоков: меню, баннеры, "хлебные крошки"
+	unlikelyRe = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|cookie|disqus|extra|foot|header|menu|modal|popup|related|remark|rss|share|shoutbox|sidebar|social|sponsor|subscribe|pagination|pager|navbar|topbar`)
+	// maybeRe защищает от удаления блоки, похожие на контент
+	maybeRe = regexp.MustCompile(`(?i)and|article|body|column|main|shadow|content`)
+	// positiveRe и negativeRe дают бонус или штраф кандидату по классу и id
+	positiveRe = regexp.MustCompile(`(?i)article|body|content|entry|hentry|main|page|post|text|blog|story|news|detail`)
+	negativeRe = regexp.MustCompile(`(?i)hidden|banner|combx|comment|contact|foot|footer|footnote|masthead|media|meta|promo|related|scroll|share|shoutbox|sidebar|sponsor|tags|tool|widget|menu|breadcrumb|cookie|nav`)
+)
+
+// SiteRules правила извлечения контента для одного сайта
+type SiteRules struct {
+	// Content CSS селекторы основного контента; если найдены, скоринг не используется
+	Content []string `json:"content"`
+	// Remove CSS селекторы блоков, которые нужно удалить перед извлечением
+	Remove []string `json:"remove"`
+}
+
+// Extractor выделяет основной контент страницы (в духе Readability)
+type Extractor struct {
+	Sites map[string]SiteRules `json:"sites"`
+}
+
+// NewExtractor создает экстрактор без правил для сайтов
+func NewExtractor() *Extractor {
+	return &Extractor{Sites: make(map[string]SiteRules)}
+}
+
+// LoadExtractorConfig загружает правила сайтов из JSON файла вида
+// {"sites": {"sop.mosmetro.ru": {"content": [".article"], "remove": [".cookie"]}}}
+func LoadExtractorConfig(filename string) (*Extractor, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	x := NewExtractor()
+	if err := json.Unmarshal(data, x); err != nil {
+		return nil, fmt.Errorf("ошибка парсинга JSON: %w", err)
+	}
+	if x.Sites == nil {
+		x.Sites = make(map[string]SiteRules)
+	}
+
+	log.Printf("Загружены правила извлечения контента для %d сайтов", len(x.Sites))
+	return x, nil
+}
+
+// AddSiteRules дополняет правила сайта
+func (x *Extractor) AddSiteRules(host string, rules SiteRules) {
+	current := x.Sites[host]
+	current.Content = append(current.Content, rules.Content...)
+	current.Remove = append(current.Remove, rules.Remove...)
+	x.Sites[host] = current
+}
+
+// rules возвращает правила для хоста с учетом общих правил "*"
+func (x *Extractor) rules(host string) SiteRules {
+	common := x.Sites["*"]
+	site := x.Sites[host]
+	return SiteRules{
+		Content: append(append([]string(nil), site.Content...), common.Content...),
+		Remove:  append(append([]string(nil), common.Remove...), site.Remove...),
+	}
+}
+
+// Extract возвращает текст основного контента страницы и дерево его разделов.
+// Заголовки, абзацы, элементы списков и строки таблиц идут отдельными строками.
+// Исходный документ не изменяется.
+func (x *Extractor) Extract(host string, page *goquery.Selection) (string, []Section) {
+	root := x.mainContent(host, page)
+	var lines []string
+	for _, n := range root {
+		lines = append(lines, renderBlocks(n)...)
+	}
+	return strings.Join(lines, "\n"), buildSections(root)
+}
+
+// mainContent возвращает узлы основного контента на копии документа
+func (x *Extractor) mainContent(host string, page *goquery.Selection) []*html.Node {
+	doc := page.Clone()
+	rules := x.rules(host)
+
+	doc.Find(noiseSelector).Remove()
+	for _, sel := range rules.Remove {
+		doc.Find(sel).Remove()
+	}
+
+	// Явные селекторы сайта имеют приоритет над эвристикой
+	for _, sel := range rules.Content {
+		if found := doc.Find(sel); found.Length() > 0 {
+			return found.Nodes
+		}
+	}
+
+	body := doc.Find("body")
+	if body.Length() == 0 {
+		body = doc
+	}
+	for _, n := range body.Nodes {
+		removeUnlikely(n)
+	}
+
+	var candidates []*html.Node
+	for _, n := range body.Nodes {
+		candidates = append(candidates, scoreCandidates(n)...)
+	}
+	if len(candidates) == 0 {
+		return body.Nodes
+	}
+	return candidates
+}
+
+// removeUnlikely удаляет блоки, которые по классу или id похожи на служебные
+func removeUnlikely(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.ElementNode {
+			switch c.Data {
+			case "html", "body", "main", "article":
+			default:
+				sig := attr(c, "class") + " " + attr(c, "id")
+				if unlikelyRe.MatchString(sig) && !maybeRe.MatchString(sig) {
+					n.RemoveChild(c)
+					c = next
+					continue
+				}
+			}
+			removeUnlikely(c)
+		}
+		c = next
+	}
+}
+
+// scoreCandidates оценивает блоки по плотности текста и ссылок и возвращает
+// лучший блок вместе с близкими по качеству соседями
+func scoreCandidates(root *html.Node) []*html.Node {
+	scores := make(map[*html.Node]float64)
+	var order []*html.Node
+
+	initCandidate := func(n *html.Node) {
+		if n == nil || n.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[n]; ok {
+			return
+		}
+		scores[n] = tagWeight(n) + classWeight(n)
+		order = append(order, n)
+	}
+
+	walkElements(root, func(n *html.Node) {
+		switch n.Data {
+		case "p", "pre", "td", "blockquote":
+		case "div":
+			if hasBlockChildren(n) {
+				return
+			}
+		default:
+			return
+		}
+
+		text := innerText(n)
+		length := utf8.RuneCountInString(text)
+		if length < 25 {
+			return
+		}
+
+		score := 1.0 + float64(strings.Count(text, ",")) + min(float64(length)/100, 3)
+		if parent := n.Parent; parent != nil {
+			initCandidate(parent)
+			scores[parent] += score
+			if grand := parent.Parent; grand != nil {
+				initCandidate(grand)
+				scores[grand] += score / 2
+			}
+		}
+	})
+
+	var top *html.Node
+	topScore := 0.0
+	for _, n := range order {
+		scores[n] *= 1 - linkDensity(n)
+		if top == nil || scores[n] > topScore {
+			top, topScore = n, scores[n]
+		}
+	}
+	if top == nil {
+		return nil
+	}
+
+	// Контент статьи иногда разбит на несколько соседних блоков
+	if top.Parent == nil {
+		return []*html.Node{top}
+	}
+	threshold := max(10, topScore*0.2)
+	var result []*html.Node
+	for sib := top.Parent.FirstChild; sib != nil; sib = sib.NextSibling {
+		if sib.Type != html.ElementNode {
+			continue
+		}
+		if sib == top {
+			result = append(result, sib)
+			continue
+		}
+		if score, ok := scores[sib]; ok && score >= threshold {
+			result = append(result, sib)
+			continue
+		}
+		if sib.Data == "p" {
+			text := innerText(sib)
+			if utf8.RuneCountInString(text) > 80 && linkDensity(sib) < 0.25 {
+				result = append(result, sib)
+			}
+		}
+	}
+	return result
+}
+
+// tagWeight начальный вес кандидата по тегу
+func tagWeight(n *html.Node) float64 {
+	switch n.Data {
+	case "article", "main":
+		return 10
+	case "div", "section":
+		return 5
+	case "pre", "td", "blockquote":
+		return 3
+	case "ol", "ul", "dl", "dd", "dt", "li", "form":
+		return -3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		return -5
+	}
+	return 0
+}
+
+// classWeight бонус или штраф по классу и id
+func classWeight(n *html.Node) float64 {
+	weight := 0.0
+	for _, value := range []string{attr(n, "class"), attr(n, "id")} {
+		if value == "" {
+			continue
+		}
+		if negativeRe.MatchString(value) {
+			weight -= 25
+		}
+		if positiveRe.MatchString(value) {
+			weight += 25
+		}
+	}
+	return weight
+}
+
+// linkDensity доля текста внутри ссылок
+func linkDensity(n *html.Node) float64 {
+	total := utf8.RuneCountInString(innerText(n))
+	if total == 0 {
+		return 0
+	}
+	links := 0
+	walkElements(n, func(el *html.Node) {
+		if el.Data == "a" {
+			links += utf8.RuneCountInString(innerText(el))
+		}
+	})
+	return min(float64(links)/float64(total), 1)
+}
+
+// blockTags элементы, которые начинают новую строку в тексте
+var blockTags = map[string]bool{
+	"address": true, "article": true, "blockquote": true, "dd": true, "div": true,
+	"dl": true, "dt": true, "figcaption": true, "figure": true, "h1": true,
+	"h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "hr": true,
+	"li": true, "main": true, "ol": true, "p": true, "pre": true, "section": true,
+	"table": true, "tbody": true, "thead": true, "tfoot": true, "tr": true, "ul": true,
+	"br": true, "caption": true,
+}
+
+// hasBlockChildren проверяет, есть ли среди детей блочные элементы
+func hasBlockChildren(n *html.Node) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && blockTags[c.Data] {
+			return true
+		}
+	}
+	return false
+}
+
+// walkElements вызывает f для всех элементов поддерева (кроме самого корня)
+func walkElements(n *html.Node, f func(*html.Node)) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			f(c)
+			walkElements(c, f)
+		}
+	}
+}
+
+// attr возвращает значение атрибута элемента
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// innerText возвращает текст поддерева с нормализованными пробелами
+func innerText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+			sb.WriteByte(' ')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// blockWriter собирает текст по строкам, начиная новую на границах блоков
+type blockWriter struct {
+	lines []string
+	cur   strings.Builder
+}
+
+func (w *blockWriter) write(s string) {
+	w.cur.WriteString(s)
+}
+
+func (w *blockWriter) flush() {
+	line := strings.Join(strings.Fields(w.cur.String()), " ")
+	if line != "" && line != "-" {
+		w.lines = append(w.lines, line)
+	}
+	w.cur.Reset()
+}
+
+// renderBlocks превращает узел в строки: заголовки, абзацы, пункты списков
+// ("- пункт") и строки таблиц ("ячейка | ячейка")
+func renderBlocks(n *html.Node) []string {
+	w := &blockWriter{}
+	renderNode(n, w)
+	w.flush()
+	return w.lines
+}
+
+func renderNode(n *html.Node, w *blockWriter) {
+	switch n.Type {
+	case html.TextNode:
+		w.write(n.Data)
+		return
+	case html.ElementNode:
+	case html.DocumentNode:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			renderNode(c, w)
+		}
+		return
+	default:
+		return
+	}
+
+	switch n.Data {
+	case "td", "th":
+		if prevElement(n) != nil {
+			w.write(" | ")
+		}
+	case "li":
+		w.flush()
+		w.write("- ")
+	case "img":
+		if alt := attr(n, "alt"); alt != "" {
+			w.write(" " + alt + " ")
+		}
+	default:
+		if blockTags[n.Data] {
+			w.flush()
+		}
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		renderNode(c, w)
+	}
+
+	if blockTags[n.Data] {
+		w.flush()
+	}
+}
+
+// prevElement возвращает предыдущий соседний элемент, пропуская текст
+func prevElement(n *html.Node) *html.Node {
+	for p := n.PrevSibling; p != nil; p = p.PrevSibling {
+		if p.Type == html.ElementNode {
+			return p
+		}
+	}
+	return nil
+}
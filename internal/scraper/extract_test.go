@@ -0,0 +1,105 @@
+package scraper
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// extractFixture страница с меню, боковой колонкой, баннером и подвалом вокруг статьи
+const extractFixture = `<html><head><title>Курсы</title><script>var menu = 1;</script></head><body>
+<header><a href="/">Главная</a> <a href="/about">О нас</a></header>
+<nav class="top"><ul><li><a href="/courses">Курсы</a></li><li><a href="/contacts">Контакты</a></li></ul></nav>
+<div class="cookie-banner">Мы используем cookie-файлы на этом сайте.</div>
+<div id="wrapper">
+  <div class="sidebar"><p>Новости центра, анонсы, мероприятия и другие материалы в колонке.</p></div>
+  <div class="article-content">
+    <h1>Подготовка машинистов</h1>
+    <p>Учебный центр проводит подготовку машинистов электропоездов, программа рассчитана на шесть месяцев.</p>
+    <p>Занятия включают теорию, практику на тренажерах, стажировку в депо и итоговую аттестацию.</p>
+    <ul><li>Очная форма</li><li>Бесплатно для сотрудников</li></ul>
+  </div>
+</div>
+<div class="promo-block"><p>Скачайте мобильное приложение метрополитена, там удобнее, быстрее и понятнее.</p></div>
+<footer><p>© Учебный центр, 2024. Все права защищены, копирование запрещено.</p></footer>
+</body></html>`
+
+func fixtureDocument(t *testing.T) *goquery.Document {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(extractFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestExtractMainContent(t *testing.T) {
+	doc := fixtureDocument(t)
+	text, sections := NewExtractor().Extract("example.com", doc.Selection)
+
+	want := "Подготовка машинистов\n" +
+		"Учебный центр проводит подготовку машинистов электропоездов, программа рассчитана на шесть месяцев.\n" +
+		"Занятия включают теорию, практику на тренажерах, стажировку в депо и итоговую аттестацию.\n" +
+		"- Очная форма\n" +
+		"- Бесплатно для сотрудников"
+	if text != want {
+		t.Errorf("Extract:\n%s\nwant:\n%s", text, want)
+	}
+	for _, noise := range []string{"Главная", "Контакты", "cookie", "Новости", "приложение", "права защищены", "var menu"} {
+		if strings.Contains(text, noise) {
+			t.Errorf("в тексте остался служебный блок %q", noise)
+		}
+	}
+	if len(sections) != 1 || sections[0].Heading != "Подготовка машинистов" {
+		t.Errorf("sections = %+v", sections)
+	}
+
+	// Исходный документ не изменяется
+	if doc.Find("nav, footer, .sidebar").Length() != 3 {
+		t.Error("Extract изменил исходный документ")
+	}
+}
+
+func TestLoadExtractorConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "extractor.json")
+	config := `{"sites": {
+		"example.com": {"content": ["#wrapper"], "remove": [".sidebar"]},
+		"*": {"remove": ["ul"]}
+	}}`
+	if err := os.WriteFile(file, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	x, err := LoadExtractorConfig(file)
+	if err != nil {
+		t.Fatalf("LoadExtractorConfig: %v", err)
+	}
+
+	// Селектор сайта выбирает блок целиком, remove сайта и общие правила применяются до него
+	text, _ := x.Extract("example.com", fixtureDocument(t).Selection)
+	want := "Подготовка машинистов\n" +
+		"Учебный центр проводит подготовку машинистов электропоездов, программа рассчитана на шесть месяцев.\n" +
+		"Занятия включают теорию, практику на тренажерах, стажировку в депо и итоговую аттестацию."
+	if text != want {
+		t.Errorf("Extract с правилами:\n%s\nwant:\n%s", text, want)
+	}
+
+	// Для другого сайта действуют только общие правила
+	text, _ = x.Extract("other.com", fixtureDocument(t).Selection)
+	if strings.Contains(text, "Очная форма") || !strings.Contains(text, "Подготовка машинистов") {
+		t.Errorf("Extract для другого сайта:\n%s", text)
+	}
+
+	broken := filepath.Join(t.TempDir(), "broken.json")
+	if err := os.WriteFile(broken, []byte(`{"sites": [`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadExtractorConfig(broken); err == nil {
+		t.Error("поврежденный конфиг загружен без ошибки")
+	}
+	if _, err := LoadExtractorConfig(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("отсутствующий конфиг загружен без ошибки")
+	}
+}
@@ -0,0 +1,235 @@
+package scraper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// PageChange описывает изменение одной страницы между обходами
+type PageChange struct {
+	URL      string `json:"url"`
+	Title    string `json:"title"`
+	Hash     string `json:"hash,omitempty"`
+	PrevHash string `json:"prev_hash,omitempty"`
+}
+
+// ChangeReport отчет об изменениях по сравнению с предыдущим обходом.
+// Removed — страницы, ответившие 404/410 или не найденные при полном обходе;
+// Unreached — страницы, до которых обход не дошел (лимит страниц, остановка,
+// временные ошибки), их судьба неизвестна.
+type ChangeReport struct {
+	GeneratedAt time.Time    `json:"generated_at"`
+	Added       []PageChange `json:"added"`
+	Modified    []PageChange `json:"modified"`
+	Removed     []PageChange `json:"removed"`
+	Unreached   []PageChange `json:"unreached"`
+	Unchanged   int          `json:"unchanged"`
+}
+
+// contentHash вычисляет хеш текста страницы
+func contentHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadPrevious загружает результаты прошлого обхода для инкрементального режима.
+// Отсутствие файла не считается ошибкой: обход просто будет полным.
+func (s *Scraper) LoadPrevious(filename string) error {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Printf("Предыдущий обход не найден (%s), выполняем полный обход", filename)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	var pages []PageData
+	if err := json.Unmarshal(data, &pages); err != nil {
+		return fmt.Errorf("ошибка парсинга JSON: %w", err)
+	}
+
+	s.previous = make(map[string]PageData, len(pages))
+	queued := make(map[string]bool, len(s.SeedURLs))
+	for _, seed := range s.SeedURLs {
+		queued[seed] = true
+	}
+	for _, page := range pages {
+		// В общем файле нескольких источников берем только свои страницы
+		if page.Source != s.Source {
+			continue
+		}
+		if page.Hash == "" {
+			page.Hash = contentHash(page.Text)
+		}
+		s.previous[s.key(page.URL)] = page
+
+		// Повторно проверяем все известные страницы, даже если на них больше нет ссылок
+		if !queued[page.URL] {
+			queued[page.URL] = true
+			s.SeedURLs = append(s.SeedURLs, page.URL)
+		}
+	}
+
+	log.Printf("Загружен предыдущий обход: %d страниц", len(s.previous))
+	return nil
+}
+
+// setConditionalHeaders добавляет If-None-Match / If-Modified-Since к запросу
+func (s *Scraper) setConditionalHeaders(r *colly.Request) {
+	prev, ok := s.previous[s.key(r.URL.String())]
+	if !ok {
+		return
+	}
+	if prev.ETag != "" {
+		r.Headers.Set("If-None-Match", prev.ETag)
+	}
+	if prev.HTTPLastModified != "" {
+		r.Headers.Set("If-Modified-Since", prev.HTTPLastModified)
+	}
+}
+
+// keepUnchanged переносит страницу из прошлого обхода после ответа 304
+// и продолжает обход по сохраненным ссылкам
+func (s *Scraper) keepUnchanged(r *colly.Request) {
+	prev, ok := s.previous[s.key(r.URL.String())]
+	if !ok {
+		s.release(r)
+		return
+	}
+
+	s.mu.Lock()
+	added := s.appendPage(r, prev)
+	if added {
+		s.unchanged[prev.URL] = true
+		log.Printf("Не изменилась: %d/%d - %s", len(s.Pages), s.MaxPages, prev.URL)
+	}
+	s.mu.Unlock()
+	if !added {
+		s.drain()
+	}
+
+	for _, link := range prev.Links {
+		s.enqueue(r, link)
+	}
+}
+
+// isNotModified проверяет, что ответ означает "страница не изменилась"
+func isNotModified(r *colly.Response) bool {
+	return r != nil && r.StatusCode == http.StatusNotModified
+}
+
+// isGone проверяет, что сервер подтвердил удаление страницы
+func isGone(status int) bool {
+	return status == http.StatusNotFound || status == http.StatusGone
+}
+
+// ChangeReport сравнивает текущий обход с предыдущим
+func (s *Scraper) ChangeReport() ChangeReport {
+	report := ChangeReport{
+		GeneratedAt: time.Now(),
+		Added:       []PageChange{},
+		Modified:    []PageChange{},
+		Removed:     []PageChange{},
+		Unreached:   []PageChange{},
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Отсутствие страницы что-то значит, только если обход не был урезан
+	complete := !s.Stopped() && !s.limited
+
+	current := make(map[string]bool, len(s.Pages))
+	for _, page := range s.Pages {
+		current[s.key(page.URL)] = true
+		for _, dup := range page.Duplicates {
+			current[s.key(dup)] = true
+		}
+		prev, existed := s.previous[s.key(page.URL)]
+		switch {
+		case !existed:
+			report.Added = append(report.Added, PageChange{URL: page.URL, Title: page.Title, Hash: page.Hash})
+		case s.unchanged[page.URL] || prev.Hash == page.Hash:
+			report.Unchanged++
+		default:
+			report.Modified = append(report.Modified, PageChange{
+				URL:      page.URL,
+				Title:    page.Title,
+				Hash:     page.Hash,
+				PrevHash: prev.Hash,
+			})
+		}
+	}
+
+	for key, prev := range s.previous {
+		if current[key] {
+			continue
+		}
+		change := PageChange{URL: prev.URL, Title: prev.Title, PrevHash: prev.Hash}
+		failed, hasFailed := s.failed[key]
+		if isGone(failed.Status) || (complete && !hasFailed) {
+			report.Removed = append(report.Removed, change)
+		} else {
+			report.Unreached = append(report.Unreached, change)
+		}
+	}
+	for _, list := range [][]PageChange{report.Removed, report.Unreached} {
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].URL < list[j].URL
+		})
+	}
+
+	return report
+}
+
+// MergeChangeReports объединяет отчеты нескольких источников
+func MergeChangeReports(reports []ChangeReport) ChangeReport {
+	merged := ChangeReport{
+		GeneratedAt: time.Now(),
+		Added:       []PageChange{},
+		Modified:    []PageChange{},
+		Removed:     []PageChange{},
+		Unreached:   []PageChange{},
+	}
+	for _, report := range reports {
+		merged.Added = append(merged.Added, report.Added...)
+		merged.Modified = append(merged.Modified, report.Modified...)
+		merged.Removed = append(merged.Removed, report.Removed...)
+		merged.Unreached = append(merged.Unreached, report.Unreached...)
+		merged.Unchanged += report.Unchanged
+	}
+	return merged
+}
+
+// SaveChangeReport сохраняет отчет об изменениях в JSON файл
+func (s *Scraper) SaveChangeReport(filename string) error {
+	return WriteChangeReport(s.ChangeReport(), filename)
+}
+
+// WriteChangeReport записывает готовый отчет об изменениях в JSON файл
+func WriteChangeReport(report ChangeReport, filename string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации: %w", err)
+	}
+
+	err = os.WriteFile(filename, data, 0644)
+	if err != nil {
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+
+	log.Printf("Изменения: добавлено %d, изменено %d, удалено %d, не проверено %d, без изменений %d (%s)",
+		len(report.Added), len(report.Modified), len(report.Removed), len(report.Unreached), report.Unchanged, filename)
+	return nil
+}
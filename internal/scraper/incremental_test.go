@@ -0,0 +1,197 @@
+package scraper
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestChangeReport(t *testing.T) {
+	const base = "https://example.com"
+	prev := func(path, hash string) PageData {
+		return PageData{URL: base + path, Title: path, Hash: hash}
+	}
+
+	tests := []struct {
+		name          string
+		limited       bool
+		stopped       bool
+		wantRemoved   []string
+		wantUnreached []string
+	}{
+		{
+			name:          "полный обход",
+			wantRemoved:   []string{base + "/gone", base + "/missing", base + "/old"},
+			wantUnreached: []string{base + "/error"},
+		},
+		{
+			name:          "лимит страниц",
+			limited:       true,
+			wantRemoved:   []string{base + "/gone", base + "/missing"},
+			wantUnreached: []string{base + "/error", base + "/old"},
+		},
+		{
+			name:          "остановка",
+			stopped:       true,
+			wantRemoved:   []string{base + "/gone", base + "/missing"},
+			wantUnreached: []string{base + "/error", base + "/old"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScraper(base+"/", 10, 0)
+			s.previous = map[string]PageData{}
+			for _, p := range []PageData{
+				prev("/same", "1"), prev("/edited", "2"), prev("/old", "3"),
+				prev("/gone", "4"), prev("/missing", "5"), prev("/error", "6"),
+			} {
+				s.previous[s.key(p.URL)] = p
+			}
+			s.Pages = []PageData{
+				{URL: base + "/same", Hash: "1"},
+				{URL: base + "/edited", Hash: "22"},
+				{URL: base + "/new", Hash: "7"},
+			}
+			s.failed[s.key(base+"/gone")] = FailedURL{URL: base + "/gone", Status: http.StatusGone}
+			s.failed[s.key(base+"/missing")] = FailedURL{URL: base + "/missing", Status: http.StatusNotFound}
+			s.failed[s.key(base+"/error")] = FailedURL{URL: base + "/error", Status: http.StatusBadGateway}
+			s.limited = tt.limited
+			if tt.stopped {
+				s.Stop()
+			}
+
+			report := s.ChangeReport()
+			if len(report.Added) != 1 || report.Added[0].URL != base+"/new" {
+				t.Errorf("Added = %+v", report.Added)
+			}
+			if len(report.Modified) != 1 || report.Modified[0].URL != base+"/edited" {
+				t.Errorf("Modified = %+v", report.Modified)
+			}
+			if report.Unchanged != 1 {
+				t.Errorf("Unchanged = %d", report.Unchanged)
+			}
+			if got := changeURLs(report.Removed); !slices.Equal(got, tt.wantRemoved) {
+				t.Errorf("Removed = %v, want %v", got, tt.wantRemoved)
+			}
+			if got := changeURLs(report.Unreached); !slices.Equal(got, tt.wantUnreached) {
+				t.Errorf("Unreached = %v, want %v", got, tt.wantUnreached)
+			}
+		})
+	}
+}
+
+func changeURLs(changes []PageChange) []string {
+	urls := []string{}
+	for _, c := range changes {
+		urls = append(urls, c.URL)
+	}
+	return urls
+}
+
+// conditionalSite сайт, где главная отдает ETag, а /about — Last-Modified;
+// при совпадении условного заголовка отвечает 304
+func conditionalSite(t *testing.T, headers *sync.Map) *httptest.Server {
+	t.Helper()
+	const lastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if v := r.Header.Get("If-None-Match"); v != "" {
+			headers.Store(r.URL.Path+" If-None-Match", v)
+		}
+		if v := r.Header.Get("If-Modified-Since"); v != "" {
+			headers.Store(r.URL.Path+" If-Modified-Since", v)
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch r.URL.Path {
+		case "/":
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			fmt.Fprint(w, `<html><body><p>Главная страница учебного центра.</p><a href="/about">О центре</a></body></html>`)
+		case "/about":
+			if r.Header.Get("If-Modified-Since") == lastModified {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("Last-Modified", lastModified)
+			fmt.Fprint(w, `<html><body><p>Об учебном центре метрополитена.</p></body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestIncrementalNotModified(t *testing.T) {
+	var headers sync.Map
+	srv := conditionalSite(t, &headers)
+	defer srv.Close()
+	prevFile := filepath.Join(t.TempDir(), "prev.json")
+
+	first := NewScraper(srv.URL+"/", 10, 0)
+	first.RetryDelay = time.Millisecond
+	first.Crawl(srv.URL + "/")
+	if len(first.Pages) != 2 {
+		t.Fatalf("первый обход: %d страниц, want 2", len(first.Pages))
+	}
+	if err := first.SaveToJSON(prevFile); err != nil {
+		t.Fatal(err)
+	}
+
+	second := NewScraper(srv.URL+"/", 10, 0)
+	second.RetryDelay = time.Millisecond
+	if err := second.LoadPrevious(prevFile); err != nil {
+		t.Fatalf("LoadPrevious: %v", err)
+	}
+	second.Crawl(srv.URL + "/")
+
+	for key, want := range map[string]string{
+		"/ If-None-Match":          `"v1"`,
+		"/about If-Modified-Since": "Mon, 02 Jan 2006 15:04:05 GMT",
+	} {
+		if got, _ := headers.Load(key); got != want {
+			t.Errorf("%s = %v, want %q", key, got, want)
+		}
+	}
+
+	// После 304 страницы берутся из прошлого обхода, /about найдена по сохраненным ссылкам
+	if len(second.Pages) != 2 {
+		t.Fatalf("второй обход: %d страниц, want 2", len(second.Pages))
+	}
+	for i, page := range second.Pages {
+		if page.Text != first.Pages[i].Text || page.Hash != first.Pages[i].Hash {
+			t.Errorf("страница %s не совпадает с прошлым обходом", page.URL)
+		}
+	}
+	report := second.ChangeReport()
+	if report.Unchanged != 2 || len(report.Added)+len(report.Modified)+len(report.Removed) != 0 {
+		t.Errorf("ChangeReport = %+v, want 2 без изменений", report)
+	}
+}
+
+func TestLoadPreviousMissingOrCorrupt(t *testing.T) {
+	dir := t.TempDir()
+
+	s := NewScraper("https://example.com/", 10, 0)
+	if err := s.LoadPrevious(filepath.Join(dir, "missing.json")); err != nil {
+		t.Errorf("отсутствующий файл: %v, want nil", err)
+	}
+	if len(s.previous) != 0 || len(s.SeedURLs) != 0 {
+		t.Errorf("без прошлого обхода previous = %v, SeedURLs = %v", s.previous, s.SeedURLs)
+	}
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	if err := os.WriteFile(corrupt, []byte(`[{"url": `), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LoadPrevious(corrupt); err == nil {
+		t.Error("поврежденный файл загружен без ошибки")
+	}
+}
@@ -0,0 +1,319 @@
+package scraper
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Типы содержимого локальных файлов
+const (
+	ContentTypeMarkdown = "text/markdown"
+	ContentTypeText     = "text/plain"
+)
+
+// localExtensions расширения локальных файлов, которые умеет разбирать LocalIngester
+var localExtensions = map[string]string{
+	".html":     ContentTypeHTML,
+	".htm":      ContentTypeHTML,
+	".md":       ContentTypeMarkdown,
+	".markdown": ContentTypeMarkdown,
+	".txt":      ContentTypeText,
+	".pdf":      ContentTypePDF,
+	".docx":     ContentTypeDOCX,
+	".xlsx":     ContentTypeXLSX,
+}
+
+// LocalIngester собирает страницы из локального каталога: выгрузок HTML,
+// Markdown, TXT и документов, которые не публикуются на сайте
+type LocalIngester struct {
+	Root string
+
+	// BaseURL префикс адреса страниц (например, адрес во внутренней сети);
+	// если не задан, используется file:// URL
+	BaseURL string
+
+	Extractor       *Extractor
+	MaxDocumentSize int
+	Source          string
+	Tags            []string
+}
+
+// NewLocalIngester создает загрузчик файлов из каталога root
+func NewLocalIngester(root string) *LocalIngester {
+	return &LocalIngester{
+		Root:            root,
+		Extractor:       NewExtractor(),
+		MaxDocumentSize: DefaultMaxDocumentSize,
+	}
+}
+
+// Ingest обходит каталог и возвращает страницы, отсортированные по адресу.
+// Файлы, которые не удалось разобрать, пропускаются с записью в лог.
+func (l *LocalIngester) Ingest() ([]PageData, error) {
+	var pages []PageData
+	err := filepath.WalkDir(l.Root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// Скрытые каталоги (.git и т.п.) пропускаем
+			if path != l.Root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		contentType := localExtensions[strings.ToLower(filepath.Ext(path))]
+		if contentType == "" {
+			return nil
+		}
+
+		page, err := l.ingestFile(path, contentType)
+		if err != nil {
+			log.Printf("Ошибка обработки %s: %v", path, err)
+			return nil
+		}
+		if page.Text == "" {
+			return nil
+		}
+		pages = append(pages, page)
+		log.Printf("Обработано: %d - %s", len(pages), page.URL)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка обхода каталога %s: %w", l.Root, err)
+	}
+
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].URL < pages[j].URL
+	})
+	return pages, nil
+}
+
+// ingestFile разбирает один файл тем же способом, что и ответы сайта
+func (l *LocalIngester) ingestFile(path, contentType string) (PageData, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return PageData{}, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+	if info.Size() > int64(l.MaxDocumentSize) {
+		return PageData{}, fmt.Errorf("файл больше %d байт", l.MaxDocumentSize)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return PageData{}, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	pageURL, err := l.fileURL(path)
+	if err != nil {
+		return PageData{}, err
+	}
+	u, _ := url.Parse(pageURL)
+
+	page := PageData{
+		URL:              pageURL,
+		ContentType:      contentType,
+		HTTPLastModified: info.ModTime().UTC().Format("Mon, 02 Jan 2006 15:04:05 GMT"),
+		Source:           l.Source,
+		Tags:             l.Tags,
+		FetchedAt:        fetchTime(),
+	}
+
+	// Текстовые файлы бывают в windows-1251, приводим к UTF-8
+	switch contentType {
+	case ContentTypeHTML, ContentTypeMarkdown, ContentTypeText:
+		data, page.Charset = decodeBody(data, "")
+	}
+
+	switch contentType {
+	case ContentTypeHTML:
+		doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+		if err != nil {
+			return PageData{}, fmt.Errorf("ошибка разбора HTML: %w", err)
+		}
+		page.Title = strings.TrimSpace(doc.Find("title").First().Text())
+		page.Text, page.Sections = l.Extractor.Extract(u.Hostname(), doc.Find("html"))
+		pageMetadata(&page, doc.Find("html"))
+	case ContentTypeMarkdown:
+		page.Title, page.Text, page.Sections = parseMarkdown(string(data))
+	case ContentTypeText:
+		page.Text = cleanText(string(data))
+	default:
+		raw, title, err := extractDocumentText(contentType, data)
+		if err != nil {
+			return PageData{}, err
+		}
+		page.Title = title
+		page.Text = cleanText(raw)
+	}
+
+	if page.Title == "" {
+		page.Title = documentTitle(u)
+	}
+	if page.Language == "" {
+		page.Language = detectLanguage(page.Text)
+	}
+	page.Length = len(page.Text)
+	page.Hash = contentHash(page.Text)
+	return page, nil
+}
+
+// fileURL формирует адрес страницы для файла
+func (l *LocalIngester) fileURL(path string) (string, error) {
+	if l.BaseURL == "" {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return "", fmt.Errorf("ошибка определения пути %s: %w", path, err)
+		}
+		return (&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String(), nil
+	}
+
+	rel, err := filepath.Rel(l.Root, path)
+	if err != nil {
+		return "", fmt.Errorf("ошибка определения пути %s: %w", path, err)
+	}
+	base, err := url.Parse(strings.TrimSuffix(l.BaseURL, "/") + "/")
+	if err != nil {
+		return "", fmt.Errorf("некорректный базовый URL %s: %w", l.BaseURL, err)
+	}
+	return base.JoinPath(filepath.ToSlash(rel)).String(), nil
+}
+
+var (
+	mdHeadingRe   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdListRe      = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	mdTableSepRe  = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+	mdInlineRe    = regexp.MustCompile("[*_`]+")
+	mdLinkRe      = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	mdUnderlineRe = regexp.MustCompile(`^(=+|-+)\s*$`)
+)
+
+// parseMarkdown строит дерево разделов по Markdown: заголовки, абзацы,
+// списки и таблицы. Возвращает заголовок первого уровня, текст и разделы.
+func parseMarkdown(source string) (title, text string, sections []Section) {
+	b := newSectionBuilder()
+	var lines []string
+
+	var list *Block
+	var table [][]string
+	flushList := func() {
+		if list != nil {
+			b.addBlock(*list)
+			lines = append(lines, list.Items...)
+			list = nil
+		}
+	}
+	flushTable := func() {
+		if len(table) > 0 {
+			b.addBlock(Block{Type: BlockTable, Rows: table})
+			for _, row := range table {
+				lines = append(lines, strings.Join(row, " | "))
+			}
+			table = nil
+		}
+	}
+	flushPara := func() {
+		if para := strings.Join(strings.Fields(b.para.String()), " "); para != "" {
+			lines = append(lines, para)
+		}
+		b.flush()
+	}
+	flushAll := func() {
+		flushPara()
+		flushList()
+		flushTable()
+	}
+
+	src := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	inCode := false
+	for i := 0; i < len(src); i++ {
+		line := src[i]
+		trimmed := strings.TrimSpace(line)
+
+		// Блоки кода переносим как абзацы без разметки
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			b.para.WriteString(line + " ")
+			continue
+		}
+
+		level, heading := 0, ""
+		if m := mdHeadingRe.FindStringSubmatch(trimmed); m != nil {
+			level, heading = len(m[1]), m[2]
+		} else if trimmed != "" && i+1 < len(src) && mdUnderlineRe.MatchString(strings.TrimSpace(src[i+1])) && b.para.Len() == 0 && list == nil {
+			// Заголовки в стиле Setext: текст, подчеркнутый === или ---
+			level, heading = 1, trimmed
+			if strings.HasPrefix(strings.TrimSpace(src[i+1]), "-") {
+				level = 2
+			}
+			i++
+		}
+		if level > 0 {
+			flushAll()
+			heading = mdInline(heading)
+			if title == "" && level == 1 {
+				title = heading
+			}
+			b.openSection(level, heading)
+			lines = append(lines, heading)
+			continue
+		}
+
+		switch {
+		case trimmed == "" || mdUnderlineRe.MatchString(trimmed):
+			// Пустая строка или горизонтальная черта завершают блок
+			flushAll()
+		case strings.HasPrefix(trimmed, "|"):
+			flushPara()
+			flushList()
+			if mdTableSepRe.MatchString(trimmed) {
+				continue
+			}
+			cells := strings.Split(strings.Trim(trimmed, "|"), "|")
+			for j := range cells {
+				cells[j] = mdInline(cells[j])
+			}
+			table = append(table, cells)
+		case mdListRe.MatchString(line):
+			m := mdListRe.FindStringSubmatch(line)
+			if list == nil {
+				flushAll()
+				list = &Block{Type: BlockList, Ordered: m[2] != "-" && m[2] != "*" && m[2] != "+"}
+			}
+			indent := strings.Repeat("  ", len(strings.ReplaceAll(m[1], "\t", "  "))/2)
+			list.Items = append(list.Items, indent+mdInline(m[3]))
+		default:
+			if list != nil && strings.HasPrefix(line, " ") {
+				// Продолжение пункта списка
+				list.Items[len(list.Items)-1] += " " + mdInline(trimmed)
+				continue
+			}
+			flushList()
+			flushTable()
+			b.para.WriteString(mdInline(strings.TrimLeft(trimmed, "> ")) + " ")
+		}
+	}
+	flushAll()
+
+	return title, strings.Join(lines, "\n"), b.sections()
+}
+
+// mdInline убирает встроенную разметку Markdown: выделение, код и ссылки
+func mdInline(s string) string {
+	s = mdLinkRe.ReplaceAllString(s, "$1")
+	s = mdInlineRe.ReplaceAllString(s, "")
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,166 @@
+package scraper
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const markdownFixture = `# Подготовка машинистов
+
+Программа для **новых** сотрудников, см. [расписание](/schedule).
+
+## Документы
+
+1. Паспорт
+2. Диплом
+   с приложением
+   - копия
+
+Сроки
+-----
+
+| Этап | Срок |
+|------|-----:|
+| Теория | 2 мес |
+| Практика | 4 мес |
+
+` + "```" + `
+код без разметки
+` + "```" + `
+
+### Контакты
+
+> Телефон учебного центра
+`
+
+func TestParseMarkdown(t *testing.T) {
+	title, text, sections := parseMarkdown(strings.ReplaceAll(markdownFixture, "\n", "\r\n"))
+
+	if title != "Подготовка машинистов" {
+		t.Errorf("title = %q", title)
+	}
+	wantText := strings.Join([]string{
+		"Подготовка машинистов",
+		"Программа для новых сотрудников, см. расписание.",
+		"Документы",
+		"Паспорт",
+		"Диплом с приложением",
+		"  копия",
+		"Сроки",
+		"Этап | Срок",
+		"Теория | 2 мес",
+		"Практика | 4 мес",
+		"код без разметки",
+		"Контакты",
+		"Телефон учебного центра",
+	}, "\n")
+	if text != wantText {
+		t.Errorf("text:\n%s\nwant:\n%s", text, wantText)
+	}
+
+	wantMarkdown := `# Подготовка машинистов
+
+Программа для новых сотрудников, см. расписание.
+
+## Документы
+
+1. Паспорт
+2. Диплом с приложением
+  - копия
+
+## Сроки
+
+| Этап | Срок |
+| --- | --- |
+| Теория | 2 мес |
+| Практика | 4 мес |
+
+код без разметки
+
+### Контакты
+
+Телефон учебного центра`
+	if got := SectionsMarkdown(sections); got != wantMarkdown {
+		t.Errorf("разделы:\n%s\nwant:\n%s", got, wantMarkdown)
+	}
+
+	// Разделы вложены по уровням заголовков
+	if len(sections) != 1 || len(sections[0].Children) != 2 {
+		t.Fatalf("дерево разделов %+v", sections)
+	}
+	contacts := sections[0].Children[1].Children[0]
+	if want := []string{"Подготовка машинистов", "Сроки", "Контакты"}; !slices.Equal(contacts.Path, want) {
+		t.Errorf("Path = %v, want %v", contacts.Path, want)
+	}
+}
+
+func TestLocalIngester(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"courses.md":        markdownFixture,
+		"info/about.html":   `<html><head><title>О центре</title></head><body><main><p>Учебный центр метрополитена готовит машинистов и диспетчеров.</p></main></body></html>`,
+		"info/notes.txt":    "Заметка для сотрудников\n",
+		"info/empty.txt":    " \n",
+		"logo.png":          "png",
+		".drafts/secret.md": "# Черновик\n\nНе публиковать.",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l := NewLocalIngester(dir)
+	l.BaseURL = "https://intranet.example/docs"
+	l.Source = "local"
+	l.Tags = []string{"internal"}
+	pages, err := l.Ingest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Пустые, скрытые и неподдерживаемые файлы пропускаются; порядок по адресу
+	want := []struct{ url, title, contentType string }{
+		{"https://intranet.example/docs/courses.md", "Подготовка машинистов", ContentTypeMarkdown},
+		{"https://intranet.example/docs/info/about.html", "О центре", ContentTypeHTML},
+		{"https://intranet.example/docs/info/notes.txt", "notes", ContentTypeText},
+	}
+	if len(pages) != len(want) {
+		t.Fatalf("страниц %d, want %d: %+v", len(pages), len(want), pages)
+	}
+	for i, w := range want {
+		page := pages[i]
+		if page.URL != w.url || page.Title != w.title || page.ContentType != w.contentType {
+			t.Errorf("страница %d: %s %q %s, want %s %q %s", i, page.URL, page.Title, page.ContentType, w.url, w.title, w.contentType)
+		}
+		if page.Source != "local" || !slices.Equal(page.Tags, []string{"internal"}) || page.Language != "ru" {
+			t.Errorf("%s: source %q, tags %v, language %q", page.URL, page.Source, page.Tags, page.Language)
+		}
+		if page.Hash != contentHash(page.Text) || page.Length != len(page.Text) || page.HTTPLastModified == "" {
+			t.Errorf("%s: hash, длина или дата изменения не заполнены", page.URL)
+		}
+	}
+	if len(pages[0].Sections) != 1 || pages[0].Sections[0].Heading != "Подготовка машинистов" {
+		t.Errorf("разделы Markdown %+v", pages[0].Sections)
+	}
+	if pages[1].Text != "Учебный центр метрополитена готовит машинистов и диспетчеров." {
+		t.Errorf("текст HTML %q", pages[1].Text)
+	}
+
+	// Без BaseURL адрес — file:// путь к файлу
+	l.BaseURL = ""
+	pages, err = l.Ingest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(pages[0].URL, "file:///") || !strings.HasSuffix(pages[0].URL, "/courses.md") {
+		t.Errorf("адрес без BaseURL %s", pages[0].URL)
+	}
+}
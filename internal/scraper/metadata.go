@@ -0,0 +1,204 @@
+package scraper
+
+import (
+	"mime"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly/v2"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+)
+
+// breadcrumbSelector контейнеры "хлебных крошек" без разметки schema.org
+const breadcrumbSelector = `nav[aria-label="breadcrumb"], nav[aria-label="Breadcrumb"], .breadcrumb, .breadcrumbs, [class*="breadcrumb"]`
+
+// breadcrumbSeparators разделители, которые сайты вставляют между крошками текстом
+var breadcrumbSeparators = map[string]bool{"/": true, "|": true, ">": true, "»": true, "→": true, "›": true, "-": true, "—": true}
+
+// detectCharset определяет кодировку HTML: BOM, заголовок Content-Type, <meta charset>.
+// Если явных указаний нет, тело в UTF-8 считается UTF-8, иначе — windows-1251:
+// для русскоязычных сайтов это вероятнее, чем windows-1252 по умолчанию.
+func detectCharset(body []byte, contentType string) (encoding.Encoding, string) {
+	enc, name, certain := charset.DetermineEncoding(body, contentType)
+	if certain || name != "windows-1252" {
+		return enc, name
+	}
+	if utf8.Valid(body) {
+		return encoding.Nop, "utf-8"
+	}
+	enc, name = charset.Lookup("windows-1251")
+	return enc, name
+}
+
+// decodeBody перекодирует HTML или текст в UTF-8 и возвращает имя исходной кодировки
+func decodeBody(body []byte, contentType string) ([]byte, string) {
+	enc, name := detectCharset(body, contentType)
+	if name == "utf-8" || enc == nil {
+		return body, name
+	}
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return body, name
+	}
+	return decoded, name
+}
+
+// decodeResponse перекодирует HTML ответ без charset в заголовке.
+// Ответы с charset в Content-Type Colly уже перекодировал сам.
+func (s *Scraper) decodeResponse(r *colly.Response) {
+	contentType := r.Headers.Get("Content-Type")
+	if contentType != "" && !strings.Contains(strings.ToLower(contentType), "html") {
+		return
+	}
+
+	var name string
+	if _, params, err := mime.ParseMediaType(contentType); err == nil && params["charset"] != "" {
+		if _, name = charset.Lookup(params["charset"]); name == "" {
+			name = strings.ToLower(params["charset"])
+		}
+	} else {
+		r.Body, name = decodeBody(r.Body, contentType)
+	}
+
+	s.mu.Lock()
+	s.charsets[r.Request.ID] = name
+	s.mu.Unlock()
+}
+
+// takeCharset возвращает кодировку ответа и забывает ее
+func (s *Scraper) takeCharset(r *colly.Request) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name := s.charsets[r.ID]
+	delete(s.charsets, r.ID)
+	return name
+}
+
+// pageMetadata заполняет метаданные HTML страницы: язык, описание,
+// Open Graph и "хлебные крошки"
+func pageMetadata(page *PageData, doc *goquery.Selection) {
+	page.Description = strings.TrimSpace(doc.Find(`meta[name="description"], meta[name="Description"]`).First().AttrOr("content", ""))
+
+	doc.Find(`meta[property^="og:"]`).Each(func(_ int, meta *goquery.Selection) {
+		property, _ := meta.Attr("property")
+		content := strings.TrimSpace(meta.AttrOr("content", ""))
+		if content == "" {
+			return
+		}
+		if page.OpenGraph == nil {
+			page.OpenGraph = make(map[string]string)
+		}
+		key := strings.TrimPrefix(property, "og:")
+		if _, ok := page.OpenGraph[key]; !ok {
+			page.OpenGraph[key] = content
+		}
+	})
+	if page.Description == "" {
+		page.Description = page.OpenGraph["description"]
+	}
+
+	page.Breadcrumbs = breadcrumbs(doc)
+
+	// Язык: атрибут lang, Content-Language, og:locale, затем по тексту
+	lang := doc.AttrOr("lang", "")
+	if lang == "" {
+		lang = doc.Find(`meta[http-equiv="content-language"], meta[http-equiv="Content-Language"]`).First().AttrOr("content", "")
+	}
+	if lang == "" {
+		lang = page.OpenGraph["locale"]
+	}
+	page.Language = normalizeLanguage(lang)
+	if page.Language == "" {
+		page.Language = detectLanguage(page.Text)
+	}
+}
+
+// breadcrumbs извлекает путь к странице из разметки schema.org BreadcrumbList
+// или из блока с классом breadcrumb
+func breadcrumbs(doc *goquery.Selection) []string {
+	var crumbs []string
+	add := func(text string) {
+		text = strings.Join(strings.Fields(text), " ")
+		if text == "" || breadcrumbSeparators[text] {
+			return
+		}
+		if len(crumbs) > 0 && crumbs[len(crumbs)-1] == text {
+			return
+		}
+		crumbs = append(crumbs, text)
+	}
+
+	doc.Find(`[itemtype*="BreadcrumbList"] [itemprop="itemListElement"]`).Each(func(_ int, item *goquery.Selection) {
+		if name := item.Find(`[itemprop="name"]`).First(); name.Length() > 0 {
+			add(name.AttrOr("content", name.Text()))
+		} else {
+			add(item.Text())
+		}
+	})
+	if len(crumbs) > 0 {
+		return crumbs
+	}
+
+	container := doc.Find(breadcrumbSelector).First()
+	if container.Length() == 0 {
+		return nil
+	}
+	items := container.Find("li")
+	if items.Length() == 0 {
+		items = container.Children()
+	}
+	items.Each(func(_ int, item *goquery.Selection) {
+		add(item.Text())
+	})
+	return crumbs
+}
+
+// normalizeLanguage приводит код языка к двухбуквенному: "ru-RU" -> "ru"
+func normalizeLanguage(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_,; "); i != -1 {
+		lang = lang[:i]
+	}
+	if len(lang) < 2 || len(lang) > 3 {
+		return ""
+	}
+	return lang
+}
+
+// detectLanguage определяет язык текста по доле кириллицы и латиницы.
+// Возвращает пустую строку, если букв слишком мало.
+func detectLanguage(text string) string {
+	var cyrillic, latin int
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+	switch {
+	case cyrillic+latin < 20:
+		return ""
+	case cyrillic >= latin:
+		return "ru"
+	}
+	return "en"
+}
+
+// fetchTime время загрузки страницы в формате RFC 3339
+func fetchTime() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+// lastModified дата изменения страницы: заголовок Last-Modified или lastmod из sitemap
+func (p PageData) lastModified() string {
+	if p.HTTPLastModified != "" {
+		return p.HTTPLastModified
+	}
+	return p.LastMod
+}
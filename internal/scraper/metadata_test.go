@@ -0,0 +1,114 @@
+package scraper
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// encodeText перекодирует строку из UTF-8 в однобайтовую кодировку
+func encodeText(t *testing.T, enc encoding.Encoding, s string) []byte {
+	t.Helper()
+	data, err := enc.NewEncoder().Bytes([]byte(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestCrawlDecodesCharset(t *testing.T) {
+	const text = "Учебный центр метрополитена: расписание занятий"
+	page := func(head string) string {
+		return fmt.Sprintf("<html><head>%s<title>Расписание</title></head><body><p>%s</p></body></html>", head, text)
+	}
+
+	type response struct {
+		contentType string
+		body        []byte
+	}
+	responses := map[string]response{
+		"/header": {"text/html; charset=windows-1251", encodeText(t, charmap.Windows1251, page(""))},
+		"/meta":   {"text/html", encodeText(t, charmap.KOI8R, page(`<meta charset="koi8-r">`))},
+		"/equiv": {"text/html", encodeText(t, charmap.Windows1251,
+			page(`<meta http-equiv="Content-Type" content="text/html; charset=windows-1251">`))},
+		// Без указания кодировки не-UTF-8 тело считается windows-1251
+		"/guess": {"text/html", encodeText(t, charmap.Windows1251, page(""))},
+		"/utf8":  {"text/html; charset=utf-8", []byte(page(""))},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			links := ""
+			for path := range responses {
+				links += fmt.Sprintf(`<a href="%s">%s</a> `, path, path)
+			}
+			fmt.Fprintf(w, "<html><body><p>Главная страница учебного центра.</p>%s</body></html>", links)
+			return
+		}
+		resp, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", resp.contentType)
+		w.Write(resp.body)
+	}))
+	defer srv.Close()
+
+	s := NewScraper(srv.URL+"/", 10, 0)
+	s.RetryDelay = time.Millisecond
+	s.Crawl(srv.URL + "/")
+
+	wantCharset := map[string]string{
+		"/header": "windows-1251",
+		"/meta":   "koi8-r",
+		"/equiv":  "windows-1251",
+		"/guess":  "windows-1251",
+		"/utf8":   "utf-8",
+	}
+	found := 0
+	for _, p := range s.Pages {
+		path := p.URL[len(srv.URL):]
+		want, ok := wantCharset[path]
+		if !ok {
+			continue
+		}
+		found++
+		if p.Text != text || p.Title != "Расписание" {
+			t.Errorf("%s: текст %q, заголовок %q", path, p.Text, p.Title)
+		}
+		if p.Charset != want {
+			t.Errorf("%s: кодировка %q, want %q", path, p.Charset, want)
+		}
+	}
+	if found != len(wantCharset) {
+		t.Errorf("собрано страниц в разных кодировках %d, want %d", found, len(wantCharset))
+	}
+}
+
+func TestDecodeBody(t *testing.T) {
+	const text = "Заметка для сотрудников"
+	tests := []struct {
+		name        string
+		body        []byte
+		contentType string
+		want        string
+	}{
+		{"windows-1251 без указания", encodeText(t, charmap.Windows1251, text), "", "windows-1251"},
+		{"koi8-r в заголовке", encodeText(t, charmap.KOI8R, text), "text/plain; charset=koi8-r", "koi8-r"},
+		{"utf-8", []byte(text), "", "utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, name := decodeBody(tt.body, tt.contentType)
+			if string(decoded) != text || name != tt.want {
+				t.Errorf("decodeBody = %q, %q; want %q, %q", decoded, name, text, tt.want)
+			}
+		})
+	}
+}
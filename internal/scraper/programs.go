@@ -0,0 +1,266 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"DriveHack/internal/programs"
+)
+
+// Форматы обучения (см. programs.NormalizeFormat)
+const (
+	FormatOffline = programs.FormatOffline
+	FormatOnline  = programs.FormatOnline
+	FormatBlended = programs.FormatBlended
+)
+
+// maxProgramDescription максимальная длина описания программы в символах
+const maxProgramDescription = 500
+
+// Program учебная программа или курс, извлеченные со страницы
+type Program struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+
+	// Format нормализованный формат (очно, онлайн, смешанный) или текст со страницы
+	Format string `json:"format,omitempty"`
+
+	// Duration срок обучения как на странице; Hours — объем в академических часах, если указан
+	Duration string `json:"duration,omitempty"`
+	Hours    int    `json:"hours,omitempty"`
+
+	Audience string `json:"audience,omitempty"`
+
+	// Cost стоимость как на странице; Price — цена в рублях, если удалось разобрать
+	Cost  string `json:"cost,omitempty"`
+	Price int    `json:"price,omitempty"`
+	Free  bool   `json:"free,omitempty"`
+
+	StartDates  []string `json:"start_dates,omitempty"`
+	Description string   `json:"description,omitempty"`
+
+	Source string   `json:"source,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+// Поля карточки программы
+const (
+	fieldFormat   = "format"
+	fieldDuration = "duration"
+	fieldAudience = "audience"
+	fieldCost     = "cost"
+	fieldStart    = "start"
+)
+
+// programLabels подписи полей карточки программы
+var programLabels = []struct {
+	field string
+	re    *regexp.Regexp
+}{
+	{fieldFormat, regexp.MustCompile(`(?i)^(?:формат|форма)(?:\s+(?:обучения|проведения|занятий))?$`)},
+	{fieldDuration, regexp.MustCompile(`(?i)^(?:продолжительность|длительность|срок|сроки|объ[её]м|количество\s+часов|трудо[её]мкость)(?:\s+(?:обучения|программы|курса|освоения))?$`)},
+	{fieldAudience, regexp.MustCompile(`(?i)^(?:для\s+кого|кому\s+подойд[её]т|целевая\s+аудитория|слушатели|категори[яи]\s+слушателей|участники|требования\s+к\s+слушателям)(?:\s+(?:программы|курса))?$`)},
+	{fieldCost, regexp.MustCompile(`(?i)^(?:стоимость|цена)(?:\s+(?:обучения|программы|курса|участия))?$`)},
+	{fieldStart, regexp.MustCompile(`(?i)^(?:начало|старт|даты?|ближайш(?:ий|ая|ие)|расписание)(?:\s+(?:обучения|занятий|курса|программы|начала|проведения|набор|поток|старт|потоки|группы))*$`)},
+}
+
+var (
+	// programPathRe адреса страниц программ и курсов
+	programPathRe = regexp.MustCompile(`(?i)/(?:programs?|courses?|kurs\w*|obuchenie|education|edu|training)(?:/|$)`)
+	// programTitleRe заголовки страниц программ и курсов
+	programTitleRe = regexp.MustCompile(`(?i)программ|курс|обучени|тренинг|семинар|повышени[ея] квалификации|переподготовк`)
+
+	labelLineRe  = regexp.MustCompile(`^([^:\n]{3,40}):\s*(.+)$`)
+	hoursRe      = regexp.MustCompile(`(?i)(\d+)\s*(?:академ[а-яё]*\.?\s*)?(?:час|ч\.|ак\.\s*ч)`)
+	priceRe      = regexp.MustCompile(`(?i)(\d[\d\s]*\d|\d)\s*(?:руб|₽|р\.)`)
+	dateRe       = regexp.MustCompile(`(?i)\d{1,2}\.\d{1,2}\.\d{2,4}|\d{1,2}\s+(?:января|февраля|марта|апреля|мая|июня|июля|августа|сентября|октября|ноября|декабря)(?:\s+\d{4})?`)
+	titleSplitRe = regexp.MustCompile(`\s+[|—–-]\s+`)
+)
+
+// ExtractPrograms находит страницы программ и курсов и собирает их карточки.
+// Страница считается программой, если на ней есть хотя бы два поля карточки
+// и адрес или заголовок похожи на программу, либо три поля и больше.
+func ExtractPrograms(pages []PageData) []Program {
+	var programs []Program
+	for _, page := range pages {
+		fields := programFields(page)
+		hint := programPathRe.MatchString(page.URL) || programTitleRe.MatchString(page.Title)
+		if len(fields) < 2 || len(fields) < 3 && !hint {
+			continue
+		}
+		programs = append(programs, buildProgram(page, fields))
+	}
+
+	sort.Slice(programs, func(i, j int) bool {
+		return programs[i].URL < programs[j].URL
+	})
+	return programs
+}
+
+// programField определяет поле карточки по подписи
+func programField(label string) string {
+	label = strings.TrimSpace(strings.TrimRight(label, ":"))
+	for _, l := range programLabels {
+		if l.re.MatchString(label) {
+			return l.field
+		}
+	}
+	return ""
+}
+
+// programFields собирает значения полей из разделов страницы, а для страниц
+// без разделов (документы) — из строк вида "Подпись: значение"
+func programFields(page PageData) map[string]string {
+	fields := make(map[string]string)
+	set := func(field, value string) {
+		value = strings.Join(strings.Fields(value), " ")
+		if field != "" && value != "" && fields[field] == "" {
+			fields[field] = truncateRunes(value, maxEntityContext)
+		}
+	}
+
+	var walk func(sections []Section)
+	walk = func(sections []Section) {
+		for _, section := range sections {
+			// Раздел с подписью в заголовке: значение — его содержимое
+			if field := programField(section.Heading); field != "" {
+				set(field, blocksText(section.Blocks))
+			}
+			for _, block := range section.Blocks {
+				switch block.Type {
+				case BlockTable:
+					for _, row := range block.Rows {
+						if len(row) >= 2 {
+							set(programField(row[0]), strings.Join(row[1:], " "))
+						}
+					}
+				case BlockList:
+					for _, item := range block.Items {
+						if m := labelLineRe.FindStringSubmatch(strings.TrimSpace(item)); m != nil {
+							set(programField(m[1]), m[2])
+						}
+					}
+				case BlockParagraph:
+					if m := labelLineRe.FindStringSubmatch(block.Text); m != nil {
+						set(programField(m[1]), m[2])
+					}
+				}
+			}
+			walk(section.Children)
+		}
+	}
+	walk(page.Sections)
+
+	if len(page.Sections) == 0 {
+		lines := strings.Split(page.Text, "\n")
+		for i, line := range lines {
+			line = strings.TrimSpace(line)
+			if m := labelLineRe.FindStringSubmatch(line); m != nil {
+				set(programField(m[1]), m[2])
+			} else if field := programField(line); field != "" && i+1 < len(lines) {
+				// Подпись на отдельной строке, значение на следующей
+				set(field, lines[i+1])
+			}
+		}
+	}
+	return fields
+}
+
+// blocksText склеивает текст блоков раздела
+func blocksText(blocks []Block) string {
+	var parts []string
+	for _, block := range blocks {
+		switch block.Type {
+		case BlockParagraph:
+			parts = append(parts, block.Text)
+		case BlockList:
+			parts = append(parts, strings.Join(block.Items, "; "))
+		case BlockTable:
+			for _, row := range block.Rows {
+				parts = append(parts, strings.Join(row, " "))
+			}
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// buildProgram заполняет карточку программы и разбирает числовые поля
+func buildProgram(page PageData, fields map[string]string) Program {
+	p := Program{
+		Name:     programName(page),
+		URL:      page.URL,
+		Format:   programs.NormalizeFormat(fields[fieldFormat]),
+		Duration: fields[fieldDuration],
+		Audience: fields[fieldAudience],
+		Cost:     fields[fieldCost],
+		Source:   page.Source,
+		Tags:     page.Tags,
+	}
+
+	if m := hoursRe.FindStringSubmatch(p.Duration); m != nil {
+		p.Hours, _ = strconv.Atoi(m[1])
+	}
+
+	cost := strings.ToLower(p.Cost)
+	if strings.Contains(cost, "бесплатн") || strings.Contains(cost, "за счет") || strings.Contains(cost, "за счёт") {
+		p.Free = true
+	} else if m := priceRe.FindStringSubmatch(p.Cost); m != nil {
+		p.Price, _ = strconv.Atoi(strings.Join(strings.Fields(m[1]), ""))
+	}
+
+	if start := fields[fieldStart]; start != "" {
+		p.StartDates = dateRe.FindAllString(start, -1)
+		if len(p.StartDates) == 0 {
+			p.StartDates = []string{start}
+		}
+	}
+
+	// Описание — первый абзац, не являющийся полем карточки
+	for _, line := range strings.Split(page.Text, "\n") {
+		line = strings.TrimSpace(line)
+		if len([]rune(line)) < 60 || labelLineRe.MatchString(line) {
+			continue
+		}
+		p.Description = truncateRunes(line, maxProgramDescription)
+		break
+	}
+	return p
+}
+
+// programName название программы: заголовок первого уровня или заголовок страницы без названия сайта
+func programName(page PageData) string {
+	for _, section := range page.Sections {
+		if section.Level == 1 && section.Heading != "" {
+			return section.Heading
+		}
+	}
+	if parts := titleSplitRe.Split(page.Title, 2); parts[0] != "" {
+		return strings.TrimSpace(parts[0])
+	}
+	return page.Title
+}
+
+// SavePrograms сохраняет каталог программ в JSON файл
+func SavePrograms(programs []Program, filename string) error {
+	if programs == nil {
+		programs = []Program{}
+	}
+	data, err := json.MarshalIndent(programs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации: %w", err)
+	}
+
+	err = os.WriteFile(filename, data, 0644)
+	if err != nil {
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+
+	log.Printf("Сохранено %d программ обучения в %s", len(programs), filename)
+	return nil
+}
@@ -0,0 +1,108 @@
+package scraper
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// htmlPage разбирает HTML страницы тем же экстрактором, что и при обходе
+func htmlPage(t *testing.T, pageURL, title, body string) PageData {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><body><main>" + body + "</main></body></html>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, sections := NewExtractor().Extract("example.com", doc.Selection)
+	return PageData{URL: pageURL, Title: title, Text: text, Sections: sections, Source: "sop", Tags: []string{"education"}}
+}
+
+func TestExtractPrograms(t *testing.T) {
+	pages := []PageData{
+		// Карточка программы: поля в таблице, списке и абзаце
+		htmlPage(t, "https://example.com/programs/driver", "Подготовка машинистов | Учебный центр", `
+<h1>Подготовка машинистов электропоезда</h1>
+<p>Программа профессиональной подготовки машинистов для работы на линиях московского метрополитена.</p>
+<table>
+<tr><td>Форма обучения</td><td>Очно-заочная</td></tr>
+<tr><td>Продолжительность</td><td>6 месяцев, 960 академических часов</td></tr>
+<tr><td>Стоимость</td><td>Бесплатно для сотрудников</td></tr>
+</table>
+<ul><li>Для кого: выпускники колледжей и сотрудники депо</li></ul>
+<p>Ближайший набор: 15.09.2024 и 1 февраля 2025</p>`),
+
+		// Документ без разделов: подписи в строках, одна — на отдельной строке
+		{
+			URL:   "https://example.com/files/safety.pdf",
+			Title: "Курс охраны труда",
+			Text: "Курс охраны труда\n" +
+				"Формат: дистанционно\n" +
+				"Объем: 40 ч.\n" +
+				"Стоимость\n" +
+				"12 500 руб.",
+		},
+
+		// Три поля без признаков программы в адресе и заголовке
+		htmlPage(t, "https://example.com/page/42", "Мероприятие", `
+<h1>Открытая лекция</h1>
+<p>Формат: онлайн</p><p>Длительность: 2 часа</p><p>Стоимость: 500 ₽</p>`),
+
+		// Одно поле карточки на странице новостей — не программа
+		htmlPage(t, "https://example.com/news/1", "Новости", `
+<h1>Новости центра</h1><p>Начало: 1 сентября</p>`),
+
+		// Программа по адресу, но только с одним полем
+		htmlPage(t, "https://example.com/courses/empty", "Курс", `<h1>Курс</h1><p>Формат: очно</p>`),
+	}
+
+	programs := ExtractPrograms(pages)
+	urls := make([]string, len(programs))
+	for i, p := range programs {
+		urls[i] = p.URL
+	}
+	wantURLs := []string{
+		"https://example.com/files/safety.pdf",
+		"https://example.com/page/42",
+		"https://example.com/programs/driver",
+	}
+	if !slices.Equal(urls, wantURLs) {
+		t.Fatalf("программы %v, want %v", urls, wantURLs)
+	}
+
+	safety, lecture, driver := programs[0], programs[1], programs[2]
+
+	if driver.Name != "Подготовка машинистов электропоезда" || driver.Format != FormatBlended ||
+		driver.Hours != 960 || !driver.Free || driver.Price != 0 {
+		t.Errorf("карточка машинистов: %+v", driver)
+	}
+	if driver.Audience != "выпускники колледжей и сотрудники депо" {
+		t.Errorf("аудитория %q", driver.Audience)
+	}
+	if want := []string{"15.09.2024", "1 февраля 2025"}; !slices.Equal(driver.StartDates, want) {
+		t.Errorf("даты начала %v, want %v", driver.StartDates, want)
+	}
+	if !strings.HasPrefix(driver.Description, "Программа профессиональной подготовки") {
+		t.Errorf("описание %q", driver.Description)
+	}
+	if driver.Source != "sop" || !slices.Equal(driver.Tags, []string{"education"}) {
+		t.Errorf("источник %q, теги %v", driver.Source, driver.Tags)
+	}
+
+	if safety.Name != "Курс охраны труда" || safety.Format != FormatOnline || safety.Hours != 40 ||
+		safety.Price != 12500 || safety.Free {
+		t.Errorf("карточка из документа: %+v", safety)
+	}
+
+	if lecture.Name != "Открытая лекция" || lecture.Format != FormatOnline || lecture.Price != 500 {
+		t.Errorf("карточка лекции: %+v", lecture)
+	}
+}
+
+func TestProgramNameFromTitle(t *testing.T) {
+	page := PageData{Title: "Повышение квалификации — Учебный центр"}
+	if got := programName(page); got != "Повышение квалификации" {
+		t.Errorf("programName = %q", got)
+	}
+}
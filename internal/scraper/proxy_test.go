@@ -0,0 +1,116 @@
+package scraper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testProxy HTTP прокси, который отвечает сам своим именем; в режиме down
+// отказывает с 407, что пул считает ошибкой прокси
+type testProxy struct {
+	*httptest.Server
+	name     string
+	down     atomic.Bool
+	requests atomic.Int32
+}
+
+func newTestProxy(t *testing.T, name string) *testProxy {
+	t.Helper()
+	p := &testProxy{name: name}
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.requests.Add(1)
+		if p.down.Load() {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		io.WriteString(w, p.name)
+	}))
+	t.Cleanup(p.Close)
+	return p
+}
+
+// fetchVia выполняет запрос через пул и возвращает имя ответившего прокси
+func fetchVia(t *testing.T, pool *ProxyPool) string {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, "http://example.test/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := pool.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestProxyPoolRoundRobin(t *testing.T) {
+	a, b, c := newTestProxy(t, "a"), newTestProxy(t, "b"), newTestProxy(t, "c")
+	pool, err := NewProxyPool([]string{a.URL, b.URL, c.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got string
+	for i := 0; i < 6; i++ {
+		got += fetchVia(t, pool)
+	}
+	if got != "abcabc" {
+		t.Errorf("порядок прокси %q, want %q", got, "abcabc")
+	}
+}
+
+func TestProxyPoolEvictionAndHealthCheck(t *testing.T) {
+	a, b := newTestProxy(t, "a"), newTestProxy(t, "b")
+	b.down.Store(true)
+
+	pool, err := NewProxyPool([]string{a.URL, b.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.MaxFailures = 2
+	pool.CheckURL = "http://example.test/check"
+
+	// Запросы через b повторяются через a; после двух ошибок подряд b исключен
+	for i := 0; i < 5; i++ {
+		if got := fetchVia(t, pool); got != "a" {
+			t.Fatalf("запрос %d обслужил прокси %q, want a", i, got)
+		}
+	}
+	if n := b.requests.Load(); n != 2 {
+		t.Errorf("запросов через b: %d, want 2", n)
+	}
+	if alive := pool.Alive(); alive != 1 {
+		t.Fatalf("Alive = %d, want 1", alive)
+	}
+	for _, st := range pool.Stats() {
+		if st.URL == b.URL && (st.Alive || st.Failures != 2 || st.Evictions != 1) {
+			t.Errorf("статистика b: %+v", st)
+		}
+	}
+
+	// Неработающий прокси проверка не возвращает
+	pool.HealthCheck(time.Second)
+	if alive := pool.Alive(); alive != 1 {
+		t.Fatalf("после проверки недоступного b Alive = %d, want 1", alive)
+	}
+
+	// Восстановившийся прокси возвращается в перебор
+	b.down.Store(false)
+	pool.HealthCheck(time.Second)
+	if alive := pool.Alive(); alive != 2 {
+		t.Fatalf("после проверки Alive = %d, want 2", alive)
+	}
+	got := fetchVia(t, pool) + fetchVia(t, pool)
+	if got != "ab" && got != "ba" {
+		t.Errorf("после возвращения b ответили %q, want оба прокси", got)
+	}
+}
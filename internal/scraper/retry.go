@@ -0,0 +1,259 @@
+package scraper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+const (
+	// DefaultMaxRetries число повторов запроса после временной ошибки
+	DefaultMaxRetries = 3
+	// DefaultRetryDelay начальная задержка перед повтором, удваивается с каждой попыткой
+	DefaultRetryDelay = 2 * time.Second
+	// maxRetryDelay верхняя граница задержки повтора и замедления хоста
+	maxRetryDelay = 2 * time.Minute
+)
+
+// FailedURL адрес, который не удалось загрузить после всех попыток
+type FailedURL struct {
+	URL      string `json:"url"`
+	Status   int    `json:"status,omitempty"`
+	Error    string `json:"error"`
+	Attempts int    `json:"attempts"`
+}
+
+// hostState состояние вежливости для одного хоста
+type hostState struct {
+	// delay дополнительная задержка перед запросами: растет при ошибках, уменьшается при успехах
+	delay time.Duration
+	// until до этого момента запросы к хосту не отправляются (Retry-After)
+	until time.Time
+}
+
+// isRetryable определяет, стоит ли повторять запрос: 429, 5xx и сетевые ошибки.
+// Отказ Colly идти по редиректу (чужой домен, уже посещенный адрес) не временный.
+func isRetryable(r *colly.Response, err error) bool {
+	var visited *colly.AlreadyVisitedError
+	switch {
+	case errors.Is(err, colly.ErrAbortedAfterHeaders),
+		errors.Is(err, colly.ErrForbiddenDomain),
+		errors.Is(err, colly.ErrForbiddenURL),
+		errors.Is(err, colly.ErrNoURLFiltersMatch),
+		errors.As(err, &visited):
+		return false
+	}
+	switch {
+	case r.StatusCode == 0:
+		return true
+	case r.StatusCode == http.StatusTooManyRequests:
+		return true
+	case r.StatusCode >= 500 && r.StatusCode != http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// retryAfter разбирает заголовок Retry-After (секунды или HTTP дата)
+func retryAfter(r *colly.Response) time.Duration {
+	if r.Headers == nil {
+		return 0
+	}
+	value := r.Headers.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+// backoff задержка перед попыткой attempt (с 1): экспонента со случайным разбросом.
+// При RetryDelay <= 0 повтор отправляется сразу.
+func (s *Scraper) backoff(attempt int) time.Duration {
+	if s.RetryDelay <= 0 {
+		return 0
+	}
+	// Сдвиг, выходящий за maxRetryDelay (или за int64), ограничиваем сверху
+	delay := maxRetryDelay
+	if shift := attempt - 1; shift < 63 && s.RetryDelay <= maxRetryDelay>>shift {
+		delay = s.RetryDelay << shift
+	}
+	// Разброс ±50%, чтобы повторы параллельных запросов не совпадали
+	return delay/2 + time.Duration(rand.Int63n(int64(delay)+1))
+}
+
+// retryWait задержка перед повтором: backoff, но не меньше Retry-After сервера
+func (s *Scraper) retryWait(r *colly.Response, attempt int) time.Duration {
+	return max(retryAfter(r), s.backoff(attempt))
+}
+
+// retry повторяет запрос после временной ошибки. Возвращает false,
+// если попытки исчерпаны и URL записан в список неудачных.
+func (s *Scraper) retry(r *colly.Response, err error) bool {
+	host := r.Request.URL.Hostname()
+	urlStr := r.Request.URL.String()
+	key := s.key(urlStr)
+	wait := retryAfter(r)
+
+	retryable := isRetryable(r, err)
+
+	s.mu.Lock()
+	if retryable {
+		s.slowDown(host, wait)
+	}
+	attempt := s.retries[key] + 1
+	if !retryable || attempt > s.MaxRetries {
+		s.failed[key] = FailedURL{URL: urlStr, Status: r.StatusCode, Error: err.Error(), Attempts: attempt}
+		delete(s.inFlight, r.Request.ID)
+		s.mu.Unlock()
+		s.drain()
+		return false
+	}
+	s.retries[key] = attempt
+
+	// Освобождаем URL, чтобы повтор прошел проверку в OnRequest; до повтора он в фронтире
+	delete(s.inFlight, r.Request.ID)
+	delete(s.VisitedURLs, key)
+	s.frontier[key] = urlStr
+	s.mu.Unlock()
+
+	wait = s.retryWait(r, attempt)
+	log.Printf("Повтор %d/%d через %v: %s (%v)", attempt, s.MaxRetries, wait.Round(time.Millisecond), urlStr, err)
+
+	// Колбэк не ждет: повтор ставится в очередь по таймеру и отправляется
+	// из следующего колбэка или из Crawl, если Colly к тому времени простаивает
+	s.mu.Lock()
+	s.retryTimers++
+	s.mu.Unlock()
+	time.AfterFunc(wait, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.retryTimers--
+		s.retryDue = append(s.retryDue, r.Request)
+		s.retryCond.Broadcast()
+	})
+	return true
+}
+
+// sendRetries отправляет повторы, время которых пришло. Вызывается только
+// из колбэков Colly или из Crawl после Collector.Wait: Retry, вызванный
+// из таймера напрямую, мог бы гоняться с Collector.Wait.
+// После остановки повторы не отправляются, их URL остаются в фронтире.
+func (s *Scraper) sendRetries() {
+	if s.Stopped() {
+		return
+	}
+	s.mu.Lock()
+	due := s.retryDue
+	s.retryDue = nil
+	s.mu.Unlock()
+
+	for _, r := range due {
+		if err := r.Retry(); err != nil {
+			log.Printf("Ошибка повтора %s: %v", r.URL, err)
+		}
+	}
+}
+
+// waitRetries ждет, пока подойдет время хотя бы одного повтора.
+// Возвращает false, если повторов не осталось или обход остановлен.
+func (s *Scraper) waitRetries() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.retryDue) == 0 && s.retryTimers > 0 && !s.Stopped() {
+		s.retryCond.Wait()
+	}
+	return len(s.retryDue) > 0 && !s.Stopped()
+}
+
+// slowDown замедляет запросы к хосту после ошибки. Вызывается под s.mu.
+func (s *Scraper) slowDown(host string, pause time.Duration) {
+	state := s.hosts[host]
+	if state == nil {
+		state = &hostState{}
+		s.hosts[host] = state
+	}
+	state.delay = min(max(state.delay*2, s.RetryDelay), maxRetryDelay)
+	if pause > 0 {
+		state.until = time.Now().Add(min(pause, maxRetryDelay))
+	}
+}
+
+// speedUp постепенно снимает замедление хоста после успешного ответа
+func (s *Scraper) speedUp(host string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state := s.hosts[host]; state != nil {
+		state.delay /= 2
+		if state.delay < s.delay/2 {
+			delete(s.hosts, host)
+		}
+	}
+}
+
+// throttle ждет, пока к хосту можно снова обращаться
+func (s *Scraper) throttle(host string) {
+	s.mu.Lock()
+	state := s.hosts[host]
+	var wait time.Duration
+	if state != nil {
+		wait = max(time.Until(state.until), state.delay)
+	}
+	s.mu.Unlock()
+
+	if wait <= 0 {
+		return
+	}
+	select {
+	case <-time.After(wait):
+	case <-s.stopCh:
+	}
+}
+
+// FailedURLs возвращает адреса, которые не удалось загрузить, отсортированные по URL
+func (s *Scraper) FailedURLs() []FailedURL {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	failed := make([]FailedURL, 0, len(s.failed))
+	for _, f := range s.failed {
+		failed = append(failed, f)
+	}
+	sort.Slice(failed, func(i, j int) bool {
+		return failed[i].URL < failed[j].URL
+	})
+	return failed
+}
+
+// SaveFailedURLs сохраняет список неудачных URL в JSON файл
+func SaveFailedURLs(failed []FailedURL, filename string) error {
+	if failed == nil {
+		failed = []FailedURL{}
+	}
+	data, err := json.MarshalIndent(failed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации: %w", err)
+	}
+
+	err = os.WriteFile(filename, data, 0644)
+	if err != nil {
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+
+	log.Printf("Не удалось загрузить %d URL, список сохранен в %s", len(failed), filename)
+	return nil
+}
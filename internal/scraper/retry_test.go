@@ -0,0 +1,239 @@
+package scraper
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// requestLog записывает пути запросов к тестовому серверу
+type requestLog struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (l *requestLog) add(path string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.paths = append(l.paths, path)
+	n := 0
+	for _, p := range l.paths {
+		if p == path {
+			n++
+		}
+	}
+	return n
+}
+
+func (l *requestLog) list() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.paths...)
+}
+
+// flakySite сайт, где /flaky отвечает 503 с первого раза, /down — всегда,
+// а /away уводит на чужой домен
+func flakySite(t *testing.T, log *requestLog) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := log.add(r.URL.Path)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch r.URL.Path {
+		case "/":
+			// Страницы на втором хосте того же сервера: замедление
+			// хоста после ошибки их не касается
+			links := `<a href="/flaky">flaky</a>`
+			host := strings.Replace(r.Host, "127.0.0.1", "localhost", 1)
+			for i := 0; i < 4; i++ {
+				links += fmt.Sprintf(` <a href="http://%s/page%d">page</a>`, host, i)
+			}
+			links += ` <a href="/away">away</a>`
+			fmt.Fprintf(w, "<html><body><p>Главная страница учебного центра.</p>%s</body></html>", links)
+		case "/flaky":
+			if n == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprint(w, "<html><body><p>Страница, ответившая со второго раза.</p></body></html>")
+		case "/down":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/away":
+			http.Redirect(w, r, "http://elsewhere.invalid/", http.StatusFound)
+		default:
+			fmt.Fprintf(w, "<html><body><p>Страница %s учебного центра.</p></body></html>", r.URL.Path)
+		}
+	}))
+}
+
+func TestRetryDoesNotBlockCrawl(t *testing.T) {
+	var log requestLog
+	srv := flakySite(t, &log)
+	defer srv.Close()
+
+	// Последовательный обход: ожидание повтора в колбэке остановило бы все запросы
+	s := NewScraper(srv.URL+"/", 100, 0)
+	s.SetAllowedDomains([]string{"127.0.0.1", "localhost"})
+	s.RetryDelay = time.Second
+	s.Crawl(srv.URL + "/")
+
+	paths := log.list()
+	var flaky []int
+	for i, p := range paths {
+		if p == "/flaky" {
+			flaky = append(flaky, i)
+		}
+	}
+	if len(flaky) != 2 {
+		t.Fatalf("запросов /flaky: %d, want 2 (%v)", len(flaky), paths)
+	}
+	for i := 0; i < 4; i++ {
+		page := fmt.Sprintf("/page%d", i)
+		if idx := slices.Index(paths, page); idx < 0 || idx > flaky[1] {
+			t.Errorf("%s запрошена после повтора /flaky: %v", page, paths)
+		}
+	}
+	if failed := s.FailedURLs(); len(failed) != 1 || !strings.HasSuffix(failed[0].URL, "/away") {
+		t.Errorf("FailedURLs = %+v, want только /away", failed)
+	}
+	if len(s.Pages) != 6 {
+		t.Errorf("собрано %d страниц, want 6", len(s.Pages))
+	}
+
+	// Редирект на чужой домен не повторяется
+	if n := strings.Count(strings.Join(paths, " "), "/away"); n != 1 {
+		t.Errorf("запросов /away: %d, want 1", n)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	var log requestLog
+	srv := flakySite(t, &log)
+	defer srv.Close()
+
+	s := NewScraper(srv.URL+"/down", 100, 0)
+	s.MaxRetries = 2
+	s.RetryDelay = time.Millisecond
+	s.SetConcurrency(4, 4)
+	s.Crawl(srv.URL + "/down")
+
+	if got := len(log.list()); got != 3 {
+		t.Errorf("запросов /down: %d, want 3", got)
+	}
+	failed := s.FailedURLs()
+	if len(failed) != 1 || failed[0].Status != http.StatusServiceUnavailable || failed[0].Attempts != 3 {
+		t.Errorf("FailedURLs = %+v", failed)
+	}
+}
+
+func TestStopDuringRetryWait(t *testing.T) {
+	var log requestLog
+	srv := flakySite(t, &log)
+	defer srv.Close()
+
+	s := NewScraper(srv.URL+"/down", 100, 0)
+	s.RetryDelay = time.Minute
+	time.AfterFunc(200*time.Millisecond, s.Stop)
+
+	start := time.Now()
+	s.Crawl(srv.URL + "/down")
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Crawl после остановки ждал повтора %v", elapsed)
+	}
+	if cp := s.snapshot(); !slices.Contains(cp.Frontier, srv.URL+"/down") {
+		t.Errorf("Frontier = %v: отложенный повтор потерян", cp.Frontier)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	s := NewScraper("https://example.com/", 10, 0)
+	s.RetryDelay = time.Second
+
+	tests := []struct {
+		attempt int
+		base    time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{8, maxRetryDelay},  // 128 с больше верхней границы
+		{70, maxRetryDelay}, // сдвиг за пределы int64
+	}
+	for _, tt := range tests {
+		// Разброс ±50% вокруг базовой задержки
+		for i := 0; i < 100; i++ {
+			if d := s.backoff(tt.attempt); d < tt.base/2 || d > tt.base*3/2 {
+				t.Fatalf("backoff(%d) = %v вне [%v, %v]", tt.attempt, d, tt.base/2, tt.base*3/2)
+			}
+		}
+	}
+
+	// Нулевая задержка означает повтор без ожидания, а не максимальную паузу
+	s.RetryDelay = 0
+	for _, attempt := range []int{1, 3, 70} {
+		if d := s.backoff(attempt); d != 0 {
+			t.Errorf("backoff(%d) без задержки = %v, want 0", attempt, d)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	response := func(value string) *colly.Response {
+		headers := http.Header{}
+		if value != "" {
+			headers.Set("Retry-After", value)
+		}
+		return &colly.Response{StatusCode: http.StatusTooManyRequests, Headers: &headers}
+	}
+
+	if d := retryAfter(response("30")); d != 30*time.Second {
+		t.Errorf("Retry-After в секундах: %v", d)
+	}
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if d := retryAfter(response(date)); d < 59*time.Minute || d > time.Hour {
+		t.Errorf("Retry-After датой: %v", d)
+	}
+	for _, value := range []string{"", "-5", "скоро"} {
+		if d := retryAfter(response(value)); d != 0 {
+			t.Errorf("Retry-After %q: %v, want 0", value, d)
+		}
+	}
+	if d := retryAfter(&colly.Response{}); d != 0 {
+		t.Errorf("ответ без заголовков: %v", d)
+	}
+
+	// Повтор ждет не меньше, чем просит сервер, даже если backoff короче
+	s := NewScraper("https://example.com/", 10, 0)
+	s.RetryDelay = time.Millisecond
+	if d := s.retryWait(response("30"), 1); d != 30*time.Second {
+		t.Errorf("retryWait с Retry-After: %v, want 30s", d)
+	}
+	if d := s.retryWait(response(""), 1); d > 2*time.Millisecond {
+		t.Errorf("retryWait без Retry-After: %v", d)
+	}
+}
+
+func TestRetryWithoutDelay(t *testing.T) {
+	var log requestLog
+	srv := flakySite(t, &log)
+	defer srv.Close()
+
+	s := NewScraper(srv.URL+"/down", 100, 0)
+	s.MaxRetries = 3
+	s.RetryDelay = 0
+
+	start := time.Now()
+	s.Crawl(srv.URL + "/down")
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("повторы без задержки заняли %v", elapsed)
+	}
+	if got := len(log.list()); got != 4 {
+		t.Errorf("запросов /down: %d, want 4", got)
+	}
+}
@@ -0,0 +1,115 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+
+	"github.com/temoto/robotstxt"
+)
+
+// robotsURL возвращает адрес robots.txt для хоста базового URL
+func robotsURL(baseURL string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("некорректный URL %s: %w", baseURL, err)
+	}
+	return u.Scheme + "://" + u.Host + "/robots.txt", nil
+}
+
+// robotsHosts возвращает адреса сайтов, для которых нужен robots.txt:
+// хост базового URL и остальные разрешенные домены с той же схемой
+func (s *Scraper) robotsHosts() []string {
+	u, err := url.Parse(s.BaseURL)
+	if err != nil {
+		return []string{s.BaseURL}
+	}
+	hosts := []string{s.BaseURL}
+	for _, domain := range s.domains {
+		if domain != u.Hostname() {
+			hosts = append(hosts, u.Scheme+"://"+domain+"/")
+		}
+	}
+	return hosts
+}
+
+// LoadRobots загружает robots.txt каждого разрешенного хоста, применяет Disallow
+// и Crawl-delay и возвращает список sitemap, указанных в файлах. Хост, чей
+// robots.txt загрузить не удалось, обходится без ограничений.
+func (s *Scraper) LoadRobots() ([]string, error) {
+	var (
+		sitemaps []string
+		errs     []error
+	)
+	for _, host := range s.robotsHosts() {
+		found, err := s.loadRobots(host)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sitemaps = append(sitemaps, found...)
+	}
+	return sitemaps, errors.Join(errs...)
+}
+
+// loadRobots загружает robots.txt одного хоста
+func (s *Scraper) loadRobots(hostURL string) ([]string, error) {
+	robotsAddr, err := robotsURL(hostURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := newRequest(robotsAddr)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки %s: %w", robotsAddr, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsSize))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения %s: %w", robotsAddr, err)
+	}
+
+	// 4xx трактуется как "разрешено всё", 5xx как "запрещено всё"
+	data, err := robotstxt.FromStatusAndBytes(resp.StatusCode, body)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка разбора %s: %w", robotsAddr, err)
+	}
+
+	s.robots[req.URL.Hostname()] = data
+
+	// Задержка одна для правил всех хостов, поэтому действует наибольший
+	// Crawl-delay; он не может сделать нас быстрее заданной задержки.
+	// При чтении из WARC к серверу не обращаемся, и задержка не нужна.
+	if delay := data.FindGroup(userAgent).CrawlDelay; delay > s.delay && s.replay == nil {
+		log.Printf("robots.txt: Crawl-delay %v", delay)
+		s.delay = delay
+		s.limit.Delay = delay
+	}
+
+	log.Printf("robots.txt загружен: %s (sitemap: %d)", robotsAddr, len(data.Sitemaps))
+	return data.Sitemaps, nil
+}
+
+// allowedByRobots проверяет URL по правилам robots.txt
+func (s *Scraper) allowedByRobots(u *url.URL) bool {
+	robots := s.robots[u.Hostname()]
+	if robots == nil {
+		return true
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	// TestAgent, в отличие от группы, учитывает "запрещено всё" при 5xx
+	return robots.TestAgent(path, userAgent)
+}
@@ -0,0 +1,137 @@
+package scraper
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestLoadRobots(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("User-agent: *\nDisallow: /private/\nDisallow: /*?print=\nCrawl-delay: 2\nSitemap: http://" + r.Host + "/sitemap.xml\n"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	s := NewScraper(srv.URL+"/", 10, 100*time.Millisecond)
+	sitemaps, err := s.LoadRobots()
+	if err != nil {
+		t.Fatalf("LoadRobots: %v", err)
+	}
+	if len(sitemaps) != 1 || sitemaps[0] != srv.URL+"/sitemap.xml" {
+		t.Errorf("sitemaps = %v", sitemaps)
+	}
+	if s.delay != 2*time.Second || s.limit.Delay != 2*time.Second {
+		t.Errorf("Crawl-delay не применен: delay %v, limit %v", s.delay, s.limit.Delay)
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/", true},
+		{"/programs/", true},
+		{"/private/", false},
+		{"/private/page.html", false},
+		{"/news?print=1", false},
+		{"/news?page=2", true},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(srv.URL + tt.path)
+		if got := s.allowedByRobots(u); got != tt.want {
+			t.Errorf("allowedByRobots(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLoadRobotsCrawlDelayBelowDelay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("User-agent: *\nCrawl-delay: 1\n"))
+	}))
+	defer srv.Close()
+
+	s := NewScraper(srv.URL+"/", 10, 3*time.Second)
+	if _, err := s.LoadRobots(); err != nil {
+		t.Fatalf("LoadRobots: %v", err)
+	}
+	if s.delay != 3*time.Second {
+		t.Errorf("Crawl-delay меньше заданной задержки не должен ее уменьшать: %v", s.delay)
+	}
+}
+
+func TestLoadRobotsStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusNotFound, true},
+		{http.StatusServiceUnavailable, false},
+	}
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+		}))
+		s := NewScraper(srv.URL+"/", 10, 0)
+		if _, err := s.LoadRobots(); err != nil {
+			t.Fatalf("LoadRobots: %v", err)
+		}
+		u, _ := url.Parse(srv.URL + "/page")
+		if got := s.allowedByRobots(u); got != tt.want {
+			t.Errorf("статус %d: allowedByRobots = %v, want %v", tt.status, got, tt.want)
+		}
+		srv.Close()
+	}
+}
+
+func TestLoadRobotsAllowedDomains(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Host {
+		case "example.test":
+			w.Write([]byte("User-agent: *\nDisallow: /admin/\n"))
+		case "docs.example.test":
+			w.Write([]byte("User-agent: *\nDisallow: /drafts/\nSitemap: http://docs.example.test/sitemap.xml\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	s := NewScraper("http://example.test/", 10, 0)
+	s.SetAllowedDomains([]string{"example.test", "docs.example.test", "files.example.test"})
+	// Все хосты обслуживает тестовый сервер
+	s.HTTPClient.Transport = &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+		},
+	}
+
+	sitemaps, err := s.LoadRobots()
+	if err != nil {
+		t.Fatalf("LoadRobots: %v", err)
+	}
+	if len(sitemaps) != 1 || sitemaps[0] != "http://docs.example.test/sitemap.xml" {
+		t.Errorf("sitemaps = %v", sitemaps)
+	}
+
+	tests := []struct {
+		raw  string
+		want bool
+	}{
+		{"http://example.test/admin/", false},
+		{"http://example.test/drafts/", true},
+		{"http://docs.example.test/drafts/1", false},
+		{"http://docs.example.test/admin/", true},
+		{"http://files.example.test/admin/", true},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.raw)
+		if got := s.allowedByRobots(u); got != tt.want {
+			t.Errorf("allowedByRobots(%s) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
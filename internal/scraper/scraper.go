@@ -2,31 +2,102 @@ package scraper
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"net/url"
 	"os"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gocolly/colly/v2"
+	"github.com/temoto/robotstxt"
 )
 
+// userAgent используется для всех запросов скрапера
+const userAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"
+
 // PageData содержит данные одной страницы
 type PageData struct {
-	URL    string `json:"url"`
-	Title  string `json:"title"`
-	Text   string `json:"text"`
-	Length int    `json:"length"`
+	URL     string `json:"url"`
+	Title   string `json:"title"`
+	Text    string `json:"text"`
+	Length  int    `json:"length"`
+	LastMod string `json:"lastmod,omitempty"`
+
+	// ContentType тип источника: HTML страница или документ (PDF, DOCX, XLSX)
+	ContentType string `json:"content_type,omitempty"`
+
+	// Sections структура страницы: разделы по заголовкам с абзацами, списками и таблицами
+	Sections []Section `json:"sections,omitempty"`
+
+	// Поля для инкрементального обхода
+	Hash             string   `json:"hash"`
+	ETag             string   `json:"etag,omitempty"`
+	HTTPLastModified string   `json:"last_modified,omitempty"`
+	Links            []string `json:"links,omitempty"`
+
+	// Duplicates адреса страниц, объединенных с этой (canonical или почти одинаковый текст)
+	Duplicates []string `json:"duplicates,omitempty"`
+
+	// Source и Tags источник из конфигурации обхода и его теги
+	Source string   `json:"source,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+
+	// Anchors тексты ссылок на страницу с других страниц сайта
+	Anchors []string `json:"anchors,omitempty"`
+
+	// Метаданные страницы: язык (ru, en), исходная кодировка, meta description,
+	// теги Open Graph без префикса og: и "хлебные крошки"
+	Language    string            `json:"language,omitempty"`
+	Charset     string            `json:"charset,omitempty"`
+	Description string            `json:"description,omitempty"`
+	OpenGraph   map[string]string `json:"og,omitempty"`
+	Breadcrumbs []string          `json:"breadcrumbs,omitempty"`
+
+	// FetchedAt время загрузки (RFC 3339), Depth глубина обхода (1 — стартовая страница)
+	FetchedAt string `json:"fetched_at,omitempty"`
+	Depth     int    `json:"depth"`
 }
 
-// Chunk представляет фрагмент текста
+// Chunk представляет фрагмент текста.
+// ID — порядковый номер в файле, ChunkID — стабильный хеш содержимого.
 type Chunk struct {
 	ID       int    `json:"id"`
+	ChunkID  string `json:"chunk_id"`
 	URL      string `json:"url"`
 	Title    string `json:"title"`
 	Text     string `json:"text"`
 	StartPos int    `json:"start_pos"`
 	EndPos   int    `json:"end_pos"`
+
+	Source string   `json:"source,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+
+	// Метаданные страницы для ранжирования и ссылок на источник
+	Language     string            `json:"language,omitempty"`
+	Charset      string            `json:"charset,omitempty"`
+	Description  string            `json:"description,omitempty"`
+	OpenGraph    map[string]string `json:"og,omitempty"`
+	Breadcrumbs  []string          `json:"breadcrumbs,omitempty"`
+	LastModified string            `json:"last_modified,omitempty"`
+	FetchedAt    string            `json:"fetched_at,omitempty"`
+	Depth        int               `json:"depth"`
+
+	// Anchors тексты входящих ссылок на страницу, отдельное поле BM25F
+	Anchors []string `json:"anchors,omitempty"`
+
+	// Section цепочка заголовков раздела страницы (режим heading).
+	// Kind "section" у родительских разделов, ParentID — ID родителя у дочерних чанков
+	Section  []string `json:"section,omitempty"`
+	Kind     string   `json:"kind,omitempty"`
+	ParentID *int     `json:"parent_id,omitempty"`
 }
 
 // Scraper обходит сайт и собирает данные
@@ -36,56 +107,146 @@ type Scraper struct {
 	Pages       []PageData
 	Collector   *colly.Collector
 	MaxPages    int
+	HTTPClient  *http.Client
+	SeedURLs    []string
+	LastMod     map[string]string // lastmod из sitemap по нормализованному URL
+
+	// MaxDocumentSize ограничение размера PDF/DOCX/XLSX в байтах
+	MaxDocumentSize int
+
+	// Extractor выделяет основной контент HTML страниц
+	Extractor *Extractor
+
+	// Source и Tags проставляются всем собранным страницам
+	Source string
+	Tags   []string
+
+	// MaxRetries и RetryDelay управляют повтором запросов после 429, 5xx и сетевых ошибок
+	MaxRetries int
+	RetryDelay time.Duration
+
+	delay     time.Duration
+	limit     *colly.LimitRule
+	robots    map[string]*robotstxt.RobotsData
+	previous  map[string]PageData
+	unchanged map[string]bool
+	domains   []string
+	include   []*regexp.Regexp
+	exclude   []*regexp.Regexp
+	warc      *WARCWriter
+	replay    *WARCArchive
+	proxies   *ProxyPool
+
+	// mu защищает Pages, VisitedURLs и служебное состояние обхода,
+	// которое меняется из колбэков Colly (в асинхронном режиме — параллельно)
+	mu            sync.Mutex
+	inFlight      map[uint32]string
+	frontier      map[string]string
+	pageIndex     map[string]int
+	canonicalDups int
+	scheme        string
+	docTitle      map[string]string
+	charsets      map[uint32]string
+	retries       map[string]int
+	retryTimers   int
+	retryDue      []*colly.Request
+	retryCond     *sync.Cond
+	failed        map[string]FailedURL
+	hosts         map[string]*hostState
+	stopCh        chan struct{}
+	stats         *crawlStats
+	workers       int
+	waiting       []*colly.Request
+	limited       bool
+	stopped       atomic.Bool
 }
 
 // NewScraper создает новый скрапер
 func NewScraper(baseURL string, maxPages int, delay time.Duration) *Scraper {
+	domain := extractDomain(baseURL)
 	c := colly.NewCollector(
-		colly.AllowedDomains(extractDomain(baseURL)),
+		colly.AllowedDomains(domain),
 		colly.MaxDepth(10),
 		colly.Async(false),
+		colly.MaxBodySize(DefaultMaxDocumentSize),
 	)
 
-	// Настройки лимитов
-	c.Limit(&colly.LimitRule{
+	// Настройки лимитов; правила по хостам регистрируются в начале обхода (см. applyLimits)
+	limit := &colly.LimitRule{
 		DomainGlob:  "*",
 		Delay:       delay,
 		RandomDelay: delay / 2,
-	})
+	}
 
 	// Таймаут
 	c.SetRequestTimeout(30 * time.Second)
 
 	// User-Agent
-	c.UserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"
+	c.UserAgent = userAgent
 
-	return &Scraper{
+	scheme := ""
+	if u, err := url.Parse(baseURL); err == nil {
+		scheme = u.Scheme
+	}
+
+	s := &Scraper{
 		BaseURL:     baseURL,
 		VisitedURLs: make(map[string]bool),
 		Pages:       []PageData{},
 		Collector:   c,
 		MaxPages:    maxPages,
+		HTTPClient:  &http.Client{Timeout: 30 * time.Second},
+
+		MaxDocumentSize: DefaultMaxDocumentSize,
+		Extractor:       NewExtractor(),
+		MaxRetries:      DefaultMaxRetries,
+		RetryDelay:      DefaultRetryDelay,
+
+		LastMod:   make(map[string]string),
+		robots:    make(map[string]*robotstxt.RobotsData),
+		unchanged: make(map[string]bool),
+		inFlight:  make(map[uint32]string),
+		frontier:  make(map[string]string),
+		pageIndex: make(map[string]int),
+		docTitle:  make(map[string]string),
+		charsets:  make(map[uint32]string),
+		retries:   make(map[string]int),
+		failed:    make(map[string]FailedURL),
+		hosts:     make(map[string]*hostState),
+		stopCh:    make(chan struct{}),
+		stats:     newCrawlStats(),
+		domains:   []string{domain},
+		delay:     delay,
+		limit:     limit,
+		scheme:    scheme,
 	}
+	s.retryCond = sync.NewCond(&s.mu)
+	return s
 }
 
-// extractDomain извлекает домен из URL
+// extractDomain извлекает домен из URL (без порта, как его сравнивает Colly)
 func extractDomain(urlStr string) string {
+	if u, err := url.Parse(urlStr); err == nil && u.Host != "" {
+		return u.Hostname()
+	}
+
 	if strings.HasPrefix(urlStr, "http://") {
 		urlStr = strings.TrimPrefix(urlStr, "http://")
 	} else if strings.HasPrefix(urlStr, "https://") {
 		urlStr = strings.TrimPrefix(urlStr, "https://")
 	}
-	
+
 	if idx := strings.Index(urlStr, "/"); idx != -1 {
 		urlStr = urlStr[:idx]
 	}
-	
+
 	return urlStr
 }
 
-// shouldSkipURL проверяет, нужно ли пропустить URL
+// shouldSkipURL проверяет, нужно ли пропустить URL.
+// PDF, DOCX и XLSX скачиваются как документы; старые бинарные .doc/.xls не поддерживаются.
 func shouldSkipURL(url string) bool {
-	skipExtensions := []string{".pdf", ".jpg", ".jpeg", ".png", ".gif", ".zip", ".doc", ".docx", ".xls", ".xlsx", ".mp4", ".avi"}
+	skipExtensions := []string{".jpg", ".jpeg", ".png", ".gif", ".zip", ".doc", ".xls", ".mp4", ".avi"}
 	lowerURL := strings.ToLower(url)
 	for _, ext := range skipExtensions {
 		if strings.HasSuffix(lowerURL, ext) {
@@ -95,7 +256,24 @@ func shouldSkipURL(url string) bool {
 	return false
 }
 
-// cleanText очищает текст от лишних пробелов
+// collectLinks собирает абсолютные ссылки страницы, пригодные для обхода
+func collectLinks(e *colly.HTMLElement) []string {
+	var links []string
+	seen := make(map[string]bool)
+	for _, href := range e.ChildAttrs("a[href]", "href") {
+		link := e.Request.AbsoluteURL(href)
+		if link == "" || shouldSkipURL(link) || seen[link] {
+			continue
+		}
+		seen[link] = true
+		links = append(links, link)
+	}
+	return links
+}
+
+// cleanText очищает текст от лишних пробелов и пустых строк.
+// Переносы строк сохраняются: по ним работают нарезка на чанки
+// и удаление шаблонного текста.
 func cleanText(text string) string {
 	lines := strings.Split(text, "\n")
 	var cleaned []string
@@ -105,97 +283,432 @@ func cleanText(text string) string {
 			cleaned = append(cleaned, line)
 		}
 	}
-	return strings.Join(cleaned, " ")
+	return strings.Join(cleaned, "\n")
+}
+
+// pageCount возвращает количество собранных страниц
+func (s *Scraper) pageCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.Pages)
+}
+
+// markLimited отмечает, что часть адресов отброшена из-за лимита страниц
+func (s *Scraper) markLimited() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limited = true
+}
+
+// reserve резервирует место под страницу для запроса.
+// Страницы и запросы "в полете" считаются вместе, поэтому MaxPages
+// не превышается даже при параллельном обходе. Запрос, не поместившийся
+// в лимит, откладывается: он будет отправлен, если один из запросов
+// "в полете" завершится без новой страницы (ошибка, редирект, дубликат).
+func (s *Scraper) reserve(r *colly.Request) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := s.key(r.URL.String())
+	if s.VisitedURLs[key] {
+		delete(s.frontier, key)
+		return false
+	}
+	if len(s.Pages)+len(s.inFlight) >= s.MaxPages {
+		s.waiting = append(s.waiting, r)
+		s.frontier[key] = r.URL.String()
+		return false
+	}
+	s.VisitedURLs[key] = true
+	s.inFlight[r.ID] = r.URL.String()
+	delete(s.frontier, key)
+	return true
+}
+
+// release освобождает резерв запроса, если он не превратился в страницу
+func (s *Scraper) release(r *colly.Request) {
+	s.mu.Lock()
+	delete(s.inFlight, r.ID)
+	s.mu.Unlock()
+	s.drain()
+}
+
+// drain отправляет отложенные запросы на освободившиеся места.
+// Colly уже считает их адреса посещенными, поэтому они повторяются через Retry.
+func (s *Scraper) drain() {
+	if s.Stopped() {
+		return
+	}
+
+	s.mu.Lock()
+	var next []*colly.Request
+	free := s.MaxPages - len(s.Pages) - len(s.inFlight)
+	for len(next) < free && len(s.waiting) > 0 {
+		r := s.waiting[0]
+		s.waiting = s.waiting[1:]
+		if !s.VisitedURLs[s.key(r.URL.String())] {
+			next = append(next, r)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, r := range next {
+		if err := r.Retry(); err != nil {
+			log.Printf("Ошибка отправки отложенного запроса %s: %v", r.URL, err)
+		}
+	}
+}
+
+// unfinished проверяет, остались ли отложенные из-за лимита адреса. Вызывается под s.mu.
+func (s *Scraper) unfinished() bool {
+	for _, r := range s.waiting {
+		if !s.VisitedURLs[s.key(r.URL.String())] {
+			return true
+		}
+	}
+	return false
+}
+
+// addPage сохраняет страницу вместо резерва запроса
+func (s *Scraper) addPage(r *colly.Request, page PageData) {
+	s.mu.Lock()
+	added := s.appendPage(r, page)
+	if added {
+		log.Printf("Обработано: %d/%d - %s", len(s.Pages), s.MaxPages, page.URL)
+	}
+	s.mu.Unlock()
+
+	// Дубликат не занял место, его можно отдать отложенному запросу
+	if !added {
+		s.drain()
+	}
+}
+
+// appendPage добавляет страницу, если страницы с тем же адресом еще нет.
+// Страница, чей canonical уже собран, считается дубликатом. Вызывается под s.mu.
+func (s *Scraper) appendPage(r *colly.Request, page PageData) bool {
+	delete(s.inFlight, r.ID)
+
+	key := s.key(page.URL)
+	requestKey := s.key(r.URL.String())
+	if idx, ok := s.pageIndex[key]; ok {
+		s.canonicalDups++
+		if requestKey != key {
+			s.Pages[idx].Duplicates = append(s.Pages[idx].Duplicates, r.URL.String())
+		}
+		return false
+	}
+
+	page.Source = s.Source
+	page.Tags = s.Tags
+	s.stats.depths[r.Depth]++
+
+	// Канонический адрес считаем посещенным, чтобы не скачивать его повторно
+	s.VisitedURLs[key] = true
+	delete(s.frontier, key)
+	if requestKey != key {
+		page.Duplicates = append(page.Duplicates, r.URL.String())
+	}
+
+	s.pageIndex[key] = len(s.Pages)
+	s.Pages = append(s.Pages, page)
+	return true
 }
 
 // Crawl рекурсивно обходит сайт используя Colly
 func (s *Scraper) Crawl(startURL string) {
 	// Обработчик HTML элементов
 	s.Collector.OnHTML("html", func(e *colly.HTMLElement) {
-		// Проверяем лимит страниц
-		if len(s.Pages) >= s.MaxPages {
-			return
-		}
-
 		// Получаем заголовок
 		title := e.ChildText("title")
 		if title == "" {
 			title = e.Request.URL.String()
 		}
 
-		// Извлекаем текст, исключая скрипты, стили и навигацию
-		e.ForEach("script, style, nav, footer, header", func(_ int, el *colly.HTMLElement) {
-			el.DOM.Remove()
-		})
+		// Запоминаем ссылки, чтобы при 304 продолжить по ним обход
+		links := collectLinks(e)
 
-		text := cleanText(e.DOM.Text())
+		// Адрес страницы: <link rel="canonical"> или нормализованный URL запроса
+		pageURL := s.canonicalOf(e.Request.URL, e.ChildAttr(`link[rel="canonical"]`, "href"))
+		if pageURL == "" {
+			pageURL = NormalizeURL(e.Request.URL.String(), s.scheme)
+		}
+
+		// Извлекаем основной контент без меню, баннеров и боковых колонок
+		text, sections := s.Extractor.Extract(e.Request.URL.Hostname(), e.DOM)
 
 		// Сохраняем данные страницы
-		if text != "" {
+		if text == "" {
+			s.recordSkip(e.Request.URL.String(), SkipNoText)
+		} else {
 			pageData := PageData{
-				URL:    e.Request.URL.String(),
-				Title:  title,
-				Text:   text,
-				Length: len(text),
+				URL:         pageURL,
+				Title:       title,
+				Text:        text,
+				Length:      len(text),
+				LastMod:     s.lastModOf(e.Request.URL.String()),
+				ContentType: ContentTypeHTML,
+				Sections:    sections,
+
+				Hash:             contentHash(text),
+				ETag:             e.Response.Headers.Get("ETag"),
+				HTTPLastModified: e.Response.Headers.Get("Last-Modified"),
+				Links:            links,
+
+				Charset:   s.takeCharset(e.Request),
+				FetchedAt: fetchTime(),
+				Depth:     e.Request.Depth,
 			}
-			s.Pages = append(s.Pages, pageData)
-			log.Printf("Обработано: %d/%d - %s", len(s.Pages), s.MaxPages, e.Request.URL.String())
+			pageMetadata(&pageData, e.DOM)
+			s.addPage(e.Request, pageData)
 		}
 	})
 
 	// Обработчик ссылок
 	s.Collector.OnHTML("a[href]", func(e *colly.HTMLElement) {
-		// Проверяем лимит страниц
-		if len(s.Pages) >= s.MaxPages {
+		link := e.Attr("href")
+		abs := e.Request.AbsoluteURL(link)
+		u, err := url.Parse(abs)
+		if abs == "" || err != nil || !s.allowedDomain(u.Hostname()) {
 			return
 		}
 
-		link := e.Attr("href")
-		
 		// Пропускаем файлы
 		if shouldSkipURL(link) {
+			s.recordSkip(abs, SkipFileType)
+			return
+		}
+
+		// Граф ссылок строим по всем ссылкам собранных страниц
+		s.recordEdge(e.Request.URL.String(), abs, e.Text)
+
+		// Проверяем лимит страниц
+		if s.pageCount() >= s.MaxPages {
+			s.markLimited()
 			return
 		}
 
+		// Текст ссылки на документ обычно лучше имени файла
+		if documentType(u, "") != "" {
+			if anchor := strings.TrimSpace(e.Text); anchor != "" {
+				s.mu.Lock()
+				s.docTitle[u.String()] = anchor
+				s.mu.Unlock()
+			}
+		}
+
 		// Переходим по ссылке
-		e.Request.Visit(link)
+		s.enqueue(e.Request, link)
 	})
 
 	// Обработчик перед запросом
 	s.Collector.OnRequest(func(r *colly.Request) {
-		// Проверяем лимит страниц
-		if len(s.Pages) >= s.MaxPages {
+		// После остановки URL остаются во фронтире для чекпоинта
+		if s.Stopped() {
+			r.Abort()
+			return
+		}
+
+		// Проверяем правила robots.txt
+		if !s.allowedByRobots(r.URL) {
+			log.Printf("Запрещено robots.txt: %s", r.URL.String())
+			s.recordSkip(r.URL.String(), SkipRobots)
+			s.mu.Lock()
+			delete(s.frontier, s.key(r.URL.String()))
+			s.mu.Unlock()
+			r.Abort()
+			return
+		}
+
+		// Проверяем include/exclude источника
+		if !s.allowedByFilters(r.URL) {
+			s.recordSkip(r.URL.String(), SkipFilter)
+			s.mu.Lock()
+			delete(s.frontier, s.key(r.URL.String()))
+			s.mu.Unlock()
 			r.Abort()
 			return
 		}
 
-		// Проверяем, не посещали ли уже
-		if s.VisitedURLs[r.URL.String()] {
+		// Проверяем лимит страниц и не посещали ли уже
+		if !s.reserve(r) {
 			r.Abort()
 			return
 		}
-		s.VisitedURLs[r.URL.String()] = true
+		s.mu.Lock()
+		s.recordRequest(r)
+		s.mu.Unlock()
+
+		// Условный запрос для страниц из прошлого обхода
+		s.setConditionalHeaders(r)
+
+		// Ждем, если хост недавно отвечал ошибками или просил Retry-After
+		s.throttle(r.URL.Hostname())
+	})
+
+	// Документы слишком большого размера не скачиваем
+	s.Collector.OnResponseHeaders(func(r *colly.Response) {
+		if documentType(r.Request.URL, r.Headers.Get("Content-Type")) == "" {
+			return
+		}
+		if size, err := strconv.Atoi(r.Headers.Get("Content-Length")); err == nil && size > s.MaxDocumentSize {
+			log.Printf("Документ слишком большой (%d байт): %s", size, r.Request.URL)
+			s.recordSkip(r.Request.URL.String(), SkipTooLarge)
+			r.Request.Abort()
+		}
+	})
+
+	// Обработчик документов PDF, DOCX, XLSX
+	s.Collector.OnResponse(func(r *colly.Response) {
+		s.recordResponse(r)
+		s.speedUp(r.Request.URL.Hostname())
+
+		docType := documentType(r.Request.URL, r.Headers.Get("Content-Type"))
+		if docType == "" {
+			// HTML в кодировке без указания в заголовке перекодируем до разбора
+			s.decodeResponse(r)
+			return
+		}
+		if page, ok := s.documentPage(r, docType); ok {
+			s.addPage(r.Request, page)
+		}
 	})
 
 	// Обработчик ошибок
 	s.Collector.OnError(func(r *colly.Response, err error) {
-		log.Printf("Ошибка при обработке %s: %v", r.Request.URL, err)
+		s.recordResponse(r)
+		if errors.Is(err, colly.ErrAbortedAfterHeaders) {
+			s.release(r.Request)
+			return
+		}
+		if isNotModified(r) {
+			s.keepUnchanged(r.Request)
+			return
+		}
+
+		// Временные ошибки повторяем с нарастающей задержкой
+		if !s.retry(r, err) {
+			log.Printf("Ошибка при обработке %s: %v", r.Request.URL, err)
+		}
+		s.sendRetries()
+	})
+
+	// Страница без текста или не-HTML ответ освобождает резерв
+	s.Collector.OnScraped(func(r *colly.Response) {
+		s.release(r.Request)
+		s.takeCharset(r.Request)
+		s.sendRetries()
 	})
 
 	// Запускаем обход
+	s.applyLimits()
+	s.stats.startedAt = time.Now()
 	log.Printf("Начинаем обход с %s", startURL)
 	err := s.Collector.Visit(startURL)
 	if err != nil {
 		log.Printf("Ошибка запуска обхода: %v", err)
 	}
 
-	// Ждем завершения всех запросов
+	// Посещаем страницы из sitemap, до которых не дошли по ссылкам
+	for _, seed := range s.SeedURLs {
+		if s.Stopped() {
+			break
+		}
+		if s.pageCount() >= s.MaxPages {
+			s.markLimited()
+			break
+		}
+		s.Collector.Visit(seed)
+	}
+
+	// Ждем завершения всех запросов, включая отложенные повторы
 	s.Collector.Wait()
+	for s.waitRetries() {
+		s.sendRetries()
+		s.Collector.Wait()
+	}
+	s.stats.finishedAt = time.Now()
+	s.mu.Lock()
+	if s.unfinished() {
+		s.limited = true
+	}
+	s.mu.Unlock()
+	s.attachAnchors()
+
+	// При параллельном обходе порядок страниц случаен, делаем результат стабильным
+	if s.Collector.Async {
+		sort.Slice(s.Pages, func(i, j int) bool {
+			return s.Pages[i].URL < s.Pages[j].URL
+		})
+	}
+}
+
+// documentPage извлекает текст документа и формирует PageData
+func (s *Scraper) documentPage(r *colly.Response, docType string) (PageData, bool) {
+	urlStr := r.Request.URL.String()
+	if len(r.Body) >= s.MaxDocumentSize {
+		log.Printf("Документ превышает лимит %d байт, пропущен: %s", s.MaxDocumentSize, urlStr)
+		s.recordSkip(urlStr, SkipTooLarge)
+		return PageData{}, false
+	}
+
+	raw, docTitle, err := extractDocumentText(docType, r.Body)
+	if err != nil {
+		log.Printf("Ошибка извлечения текста из %s: %v", urlStr, err)
+		s.recordSkip(urlStr, SkipExtractor)
+		return PageData{}, false
+	}
+	text := cleanText(raw)
+	if text == "" {
+		s.recordSkip(urlStr, SkipNoText)
+		return PageData{}, false
+	}
+
+	s.mu.Lock()
+	title := s.docTitle[urlStr]
+	s.mu.Unlock()
+	if title == "" {
+		title = docTitle
+	}
+	if title == "" {
+		title = documentTitle(r.Request.URL)
+	}
+
+	return PageData{
+		URL:         NormalizeURL(urlStr, s.scheme),
+		Title:       title,
+		Text:        text,
+		Length:      len(text),
+		LastMod:     s.lastModOf(urlStr),
+		ContentType: docType,
+
+		Hash:             contentHash(text),
+		ETag:             r.Headers.Get("ETag"),
+		HTTPLastModified: r.Headers.Get("Last-Modified"),
+
+		Language:  detectLanguage(text),
+		FetchedAt: fetchTime(),
+		Depth:     r.Request.Depth,
+	}, true
+}
+
+// SetMaxDocumentSize задает ограничение размера документа в байтах
+func (s *Scraper) SetMaxDocumentSize(size int) {
+	s.MaxDocumentSize = size
+	// Colly обрезает тело по MaxBodySize, поэтому лимит должен быть не меньше
+	if s.Collector.MaxBodySize < size {
+		s.Collector.MaxBodySize = size
+	}
 }
 
 // SaveToJSON сохраняет данные в JSON файл
 func (s *Scraper) SaveToJSON(filename string) error {
-	data, err := json.MarshalIndent(s.Pages, "", "  ")
+	return SavePages(s.Pages, filename)
+}
+
+// SavePages сохраняет страницы (например, объединенные из нескольких источников) в JSON файл
+func SavePages(pages []PageData, filename string) error {
+	data, err := json.MarshalIndent(pages, "", "  ")
 	if err != nil {
 		return fmt.Errorf("ошибка сериализации: %w", err)
 	}
@@ -205,39 +718,37 @@ func (s *Scraper) SaveToJSON(filename string) error {
 		return fmt.Errorf("ошибка записи файла: %w", err)
 	}
 
-	log.Printf("Данные сохранены в %s (страниц: %d)", filename, len(s.Pages))
+	log.Printf("Данные сохранены в %s (страниц: %d)", filename, len(pages))
 	return nil
 }
 
-// SaveChunks разбивает текст на чанки и сохраняет
-func (s *Scraper) SaveChunks(filename string, chunkSize int) error {
-	var chunks []Chunk
-	chunkID := 0
-
-	for _, page := range s.Pages {
-		text := page.Text
-
-		// Разбиваем на чанки
-		for i := 0; i < len(text); i += chunkSize {
-			end := i + chunkSize
-			if end > len(text) {
-				end = len(text)
-			}
-
-			chunk := Chunk{
-				ID:       chunkID,
-				URL:      page.URL,
-				Title:    page.Title,
-				Text:     text[i:end],
-				StartPos: i,
-				EndPos:   end,
-			}
+// LoadPages загружает страницы из JSON файла, сохраненного SavePages
+func LoadPages(filename string) ([]PageData, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
 
-			chunks = append(chunks, chunk)
-			chunkID++
-		}
+	var pages []PageData
+	if err := json.Unmarshal(data, &pages); err != nil {
+		return nil, fmt.Errorf("ошибка парсинга JSON: %w", err)
 	}
+	return pages, nil
+}
+
+// SaveChunks разбивает текст на чанки и сохраняет
+func (s *Scraper) SaveChunks(filename string, opts ChunkOptions) error {
+	return SavePageChunks(s.Pages, filename, opts)
+}
+
+// SavePageChunks разбивает страницы на чанки (см. BuildChunks) и сохраняет.
+// Размер чанка и перекрытие задаются в символах, StartPos и EndPos — позиции в символах текста.
+func SavePageChunks(pages []PageData, filename string, opts ChunkOptions) error {
+	return SaveChunkList(BuildChunks(pages, opts), filename)
+}
 
+// SaveChunkList сохраняет готовые чанки в JSON файл
+func SaveChunkList(chunks []Chunk, filename string) error {
 	data, err := json.MarshalIndent(chunks, "", "  ")
 	if err != nil {
 		return fmt.Errorf("ошибка сериализации: %w", err)
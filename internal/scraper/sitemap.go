@@ -0,0 +1,166 @@
+package scraper
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	maxRobotsSize  = 512 * 1024
+	maxSitemapSize = 50 * 1024 * 1024
+	// maxSitemapDepth ограничивает вложенность sitemap index
+	maxSitemapDepth = 3
+)
+
+// sitemapEntry элемент <url> или <sitemap>
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+// sitemapDoc покрывает и <urlset>, и <sitemapindex>
+type sitemapDoc struct {
+	XMLName  xml.Name
+	URLs     []sitemapEntry `xml:"url"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+// newRequest создает GET запрос с User-Agent скрапера
+func newRequest(urlStr string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса %s: %w", urlStr, err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	return req, nil
+}
+
+// DefaultSitemapURL возвращает стандартный адрес sitemap.xml для базового URL
+func DefaultSitemapURL(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host + "/sitemap.xml"
+}
+
+// LoadSitemaps загружает sitemap (включая sitemap index и .gz)
+// и добавляет найденные страницы в очередь обхода
+func (s *Scraper) LoadSitemaps(sitemapURLs []string) error {
+	seen := make(map[string]bool)
+	queued := make(map[string]bool, len(s.SeedURLs))
+	for _, seed := range s.SeedURLs {
+		queued[seed] = true
+	}
+
+	var firstErr error
+	for _, sitemapURL := range sitemapURLs {
+		if err := s.loadSitemap(sitemapURL, 0, seen, queued); err != nil {
+			log.Printf("Ошибка обработки sitemap %s: %v", sitemapURL, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	log.Printf("Из sitemap добавлено страниц: %d", len(s.SeedURLs))
+	return firstErr
+}
+
+// loadSitemap обрабатывает один sitemap, рекурсивно раскрывая индексы
+func (s *Scraper) loadSitemap(sitemapURL string, depth int, seen, queued map[string]bool) error {
+	if seen[sitemapURL] || depth > maxSitemapDepth {
+		return nil
+	}
+	seen[sitemapURL] = true
+
+	doc, err := s.fetchSitemap(sitemapURL)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range doc.Sitemaps {
+		loc := strings.TrimSpace(child.Loc)
+		if loc == "" {
+			continue
+		}
+		if err := s.loadSitemap(loc, depth+1, seen, queued); err != nil {
+			log.Printf("Ошибка обработки sitemap %s: %v", loc, err)
+		}
+	}
+
+	for _, entry := range doc.URLs {
+		loc := strings.TrimSpace(entry.Loc)
+		u, err := url.Parse(loc)
+		if err != nil || !s.allowedDomain(u.Hostname()) {
+			continue
+		}
+		if !s.allowedByRobots(u) || !s.allowedByFilters(u) || shouldSkipURL(loc) {
+			continue
+		}
+		if lastMod := strings.TrimSpace(entry.LastMod); lastMod != "" {
+			s.LastMod[NormalizeURL(loc, s.scheme)] = lastMod
+		}
+		if !queued[loc] {
+			queued[loc] = true
+			s.SeedURLs = append(s.SeedURLs, loc)
+		}
+	}
+
+	return nil
+}
+
+// lastModOf возвращает lastmod из sitemap для адреса запроса.
+// Адреса сравниваются в нормализованном виде: в sitemap и в ссылках
+// одна и та же страница может быть записана по-разному.
+func (s *Scraper) lastModOf(raw string) string {
+	return s.LastMod[NormalizeURL(raw, s.scheme)]
+}
+
+// fetchSitemap загружает и разбирает sitemap, распаковывая gzip при необходимости
+func (s *Scraper) fetchSitemap(sitemapURL string) (*sitemapDoc, error) {
+	req, err := newRequest(sitemapURL)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус %d", resp.StatusCode)
+	}
+
+	return parseSitemap(io.LimitReader(resp.Body, maxSitemapSize))
+}
+
+// parseSitemap разбирает XML sitemap; gzip определяется по сигнатуре
+func parseSitemap(r io.Reader) (*sitemapDoc, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка распаковки gzip: %w", err)
+		}
+		defer gz.Close()
+		r = io.LimitReader(gz, maxSitemapSize)
+	} else {
+		r = br
+	}
+
+	var doc sitemapDoc
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("ошибка разбора XML: %w", err)
+	}
+	return &doc, nil
+}
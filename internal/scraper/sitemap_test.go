@@ -0,0 +1,127 @@
+package scraper
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// gzipBytes сжимает данные как sitemap.xml.gz
+func gzipBytes(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestLoadSitemaps(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		requested []string
+	)
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Path)
+		mu.Unlock()
+
+		// Цепочка индексов: /index0.xml -> /index1.xml -> ... и на каждом
+		// уровне сжатый список страниц /pagesN.xml.gz
+		var level int
+		switch {
+		case r.URL.Path == "/sitemap.xml":
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>%[1]s/about</loc><lastmod>2024-01-02</lastmod></url>
+  <url><loc>%[1]s/news?utm_source=mail&amp;b=2&amp;a=1</loc><lastmod>2024-03-04</lastmod></url>
+  <url><loc>%[1]s/private/secret</loc></url>
+  <url><loc>%[1]s/file.zip</loc></url>
+  <url><loc>http://other.example/page</loc></url>
+</urlset>`, srv.URL)
+		case r.URL.Path == "/robots.txt":
+			w.Write([]byte("User-agent: *\nDisallow: /private/\n"))
+		case strings.HasPrefix(r.URL.Path, "/index"):
+			fmt.Sscanf(r.URL.Path, "/index%d.xml", &level)
+			fmt.Fprintf(w, `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>%[1]s/index%[2]d.xml</loc></sitemap>
+  <sitemap><loc>%[1]s/pages%[3]d.xml.gz</loc></sitemap>
+</sitemapindex>`, srv.URL, level+1, level)
+		case strings.HasPrefix(r.URL.Path, "/pages"):
+			fmt.Sscanf(r.URL.Path, "/pages%d.xml.gz", &level)
+			w.Header().Set("Content-Type", "application/x-gzip")
+			w.Write(gzipBytes(t, fmt.Sprintf(`<urlset><url><loc>%s/level%d</loc></url></urlset>`, srv.URL, level)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	s := NewScraper(srv.URL+"/", 100, 0)
+	if _, err := s.LoadRobots(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LoadSitemaps([]string{srv.URL + "/sitemap.xml", srv.URL + "/index0.xml"}); err != nil {
+		t.Fatalf("LoadSitemaps: %v", err)
+	}
+
+	// Индекс index0 загружен на глубине 0, его список страниц — на глубине 1;
+	// глубже maxSitemapDepth индексы не раскрываются
+	want := []string{
+		srv.URL + "/about",
+		srv.URL + "/news?utm_source=mail&b=2&a=1",
+	}
+	for level := 0; level < maxSitemapDepth; level++ {
+		want = append(want, fmt.Sprintf("%s/level%d", srv.URL, level))
+	}
+	got := append([]string(nil), s.SeedURLs...)
+	sort.Strings(got)
+	sort.Strings(want)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("SeedURLs:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	for _, path := range requested {
+		if path == fmt.Sprintf("/index%d.xml", maxSitemapDepth+1) {
+			t.Errorf("загружен индекс глубже maxSitemapDepth: %s", path)
+		}
+	}
+
+	// lastmod ищется по нормализованному адресу запроса
+	lastMods := map[string]string{
+		srv.URL + "/about":                 "2024-01-02",
+		srv.URL + "/about#team":            "2024-01-02",
+		srv.URL + "/news?a=1&b=2":          "2024-03-04",
+		srv.URL + "/news?b=2&a=1&utm_id=3": "2024-03-04",
+		srv.URL + "/level0":                "",
+	}
+	for raw, want := range lastMods {
+		if got := s.lastModOf(raw); got != want {
+			t.Errorf("lastModOf(%s) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestParseSitemapGzip(t *testing.T) {
+	xml := `<urlset><url><loc>https://example.com/a</loc><lastmod>2024-05-06</lastmod></url></urlset>`
+	for name, data := range map[string][]byte{"plain": []byte(xml), "gzip": gzipBytes(t, xml)} {
+		doc, err := parseSitemap(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(doc.URLs) != 1 || doc.URLs[0].Loc != "https://example.com/a" || doc.URLs[0].LastMod != "2024-05-06" {
+			t.Errorf("%s: %+v", name, doc.URLs)
+		}
+	}
+}
@@ -0,0 +1,430 @@
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// Причины пропуска URL в отчете обхода
+const (
+	SkipRobots    = "запрещено robots.txt"
+	SkipFilter    = "не прошел include/exclude"
+	SkipFileType  = "неподдерживаемый тип файла"
+	SkipTooLarge  = "документ больше лимита"
+	SkipNoText    = "нет текста"
+	SkipExtractor = "ошибка извлечения текста"
+)
+
+// crawlStats счетчики обхода; защищены s.mu
+type crawlStats struct {
+	startedAt    time.Time
+	finishedAt   time.Time
+	requests     int
+	bytes        int64
+	status       map[int]int
+	contentTypes map[string]int
+	depths       map[int]int
+	durations    []time.Duration
+	requestStart map[uint32]time.Time
+	skipped      map[string]string
+	edges        map[LinkEdge]bool
+}
+
+func newCrawlStats() *crawlStats {
+	return &crawlStats{
+		status:       make(map[int]int),
+		contentTypes: make(map[string]int),
+		depths:       make(map[int]int),
+		requestStart: make(map[uint32]time.Time),
+		skipped:      make(map[string]string),
+		edges:        make(map[LinkEdge]bool),
+	}
+}
+
+// LinkEdge ссылка между страницами сайта с текстом ссылки
+type LinkEdge struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Anchor string `json:"anchor,omitempty"`
+}
+
+// GraphNode страница в графе ссылок. Crawled = false означает,
+// что на страницу есть ссылки, но она не попала в собранные данные.
+type GraphNode struct {
+	URL      string `json:"url"`
+	Title    string `json:"title,omitempty"`
+	Crawled  bool   `json:"crawled"`
+	InLinks  int    `json:"in_links"`
+	OutLinks int    `json:"out_links"`
+}
+
+// LinkGraph граф внутренних ссылок сайта
+type LinkGraph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []LinkEdge  `json:"edges"`
+}
+
+// SkippedURL пропущенный URL и причина
+type SkippedURL struct {
+	URL    string `json:"url"`
+	Reason string `json:"reason"`
+}
+
+// ResponseTimes время ответа сервера в миллисекундах
+type ResponseTimes struct {
+	Avg int64 `json:"avg_ms"`
+	P50 int64 `json:"p50_ms"`
+	P95 int64 `json:"p95_ms"`
+	Max int64 `json:"max_ms"`
+}
+
+// CrawlReport машиночитаемый отчет об обходе одного источника
+type CrawlReport struct {
+	Source       string         `json:"source,omitempty"`
+	BaseURL      string         `json:"base_url"`
+	StartedAt    time.Time      `json:"started_at"`
+	FinishedAt   time.Time      `json:"finished_at"`
+	Duration     float64        `json:"duration_sec"`
+	Pages        int            `json:"pages"`
+	Requests     int            `json:"requests"`
+	Bytes        int64          `json:"bytes"`
+	StatusCodes  map[int]int    `json:"status_codes"`
+	ContentTypes map[string]int `json:"content_types"`
+	Depths       map[int]int    `json:"depths"`
+	ResponseTime ResponseTimes  `json:"response_time"`
+	SkipReasons  map[string]int `json:"skip_reasons"`
+	Skipped      []SkippedURL   `json:"skipped"`
+	Failed       []FailedURL    `json:"failed"`
+	Duplicates   DedupStats     `json:"duplicates"`
+	// Proxies накопленная статистика пула прокси, если обход шел через прокси
+	Proxies []ProxyStats `json:"proxies,omitempty"`
+}
+
+// recordRequest запоминает время начала запроса. Вызывается под s.mu.
+func (s *Scraper) recordRequest(r *colly.Request) {
+	s.stats.requests++
+	s.stats.requestStart[r.ID] = time.Now()
+}
+
+// recordResponse учитывает код ответа, размер и время ответа
+func (s *Scraper) recordResponse(r *colly.Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats.status[r.StatusCode]++
+	s.stats.bytes += int64(len(r.Body))
+	if start, ok := s.stats.requestStart[r.Request.ID]; ok {
+		s.stats.durations = append(s.stats.durations, time.Since(start))
+		delete(s.stats.requestStart, r.Request.ID)
+	}
+}
+
+// recordSkip запоминает пропущенный URL с причиной
+func (s *Scraper) recordSkip(u, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.skipped[u] = reason
+}
+
+// recordEdge добавляет ссылку в граф
+func (s *Scraper) recordEdge(from, to, anchor string) {
+	edge := LinkEdge{
+		From:   NormalizeURL(from, s.scheme),
+		To:     NormalizeURL(to, s.scheme),
+		Anchor: strings.Join(strings.Fields(anchor), " "),
+	}
+	if edge.From == edge.To {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.edges[edge] = true
+}
+
+// LinkGraph строит граф внутренних ссылок по собранным страницам.
+// Концы ссылок приводятся к адресам узлов: варианты со слешем и адреса
+// дубликатов ведут на страницу, в которую они объединены, а совпавшие
+// после этого ссылки учитываются один раз.
+func (s *Scraper) LinkGraph() LinkGraph {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	graph := LinkGraph{Nodes: []GraphNode{}, Edges: []LinkEdge{}}
+	nodes := make(map[string]*GraphNode)
+	node := func(u string) *GraphNode {
+		key := s.key(u)
+		n, ok := nodes[key]
+		if !ok {
+			n = &GraphNode{URL: u}
+			nodes[key] = n
+		}
+		return n
+	}
+
+	for _, page := range s.Pages {
+		n := node(page.URL)
+		n.URL, n.Title, n.Crawled = page.URL, page.Title, true
+		// Адреса дубликатов ведут на ту же страницу
+		for _, dup := range page.Duplicates {
+			nodes[s.key(dup)] = n
+		}
+	}
+
+	// Порядок обхода ребер задает адрес несобранных страниц, делаем его стабильным
+	raw := make([]LinkEdge, 0, len(s.stats.edges))
+	for edge := range s.stats.edges {
+		raw = append(raw, edge)
+	}
+	sortEdges(raw)
+
+	seenEdges := make(map[LinkEdge]bool, len(raw))
+	for _, edge := range raw {
+		from, to := node(edge.From), node(edge.To)
+		if from == to {
+			continue
+		}
+		edge.From, edge.To = from.URL, to.URL
+		if seenEdges[edge] {
+			continue
+		}
+		seenEdges[edge] = true
+		graph.Edges = append(graph.Edges, edge)
+		from.OutLinks++
+		to.InLinks++
+	}
+
+	seen := make(map[*GraphNode]bool)
+	for _, n := range nodes {
+		if !seen[n] {
+			seen[n] = true
+			graph.Nodes = append(graph.Nodes, *n)
+		}
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].URL < graph.Nodes[j].URL })
+	sortEdges(graph.Edges)
+	return graph
+}
+
+// sortEdges сортирует ссылки по адресам и тексту
+func sortEdges(edges []LinkEdge) {
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Anchor < b.Anchor
+	})
+}
+
+// attachAnchors сохраняет в страницах тексты входящих ссылок:
+// они хорошо описывают страницу и пригодятся для поиска
+func (s *Scraper) attachAnchors() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	anchors := make(map[string]map[string]bool)
+	for edge := range s.stats.edges {
+		if edge.Anchor == "" {
+			continue
+		}
+		key := s.key(edge.To)
+		if anchors[key] == nil {
+			anchors[key] = make(map[string]bool)
+		}
+		anchors[key][edge.Anchor] = true
+	}
+
+	for i := range s.Pages {
+		page := &s.Pages[i]
+		set := anchors[s.key(page.URL)]
+		for _, dup := range page.Duplicates {
+			for anchor := range anchors[s.key(dup)] {
+				if set == nil {
+					set = make(map[string]bool)
+				}
+				set[anchor] = true
+			}
+		}
+		page.Anchors = page.Anchors[:0]
+		for anchor := range set {
+			if anchor != page.Title {
+				page.Anchors = append(page.Anchors, anchor)
+			}
+		}
+		sort.Strings(page.Anchors)
+		if len(page.Anchors) == 0 {
+			page.Anchors = nil
+		}
+	}
+}
+
+// Report формирует отчет об обходе; dedup — результат Deduplicate
+func (s *Scraper) Report(dedup DedupStats) CrawlReport {
+	failed := s.FailedURLs()
+	var proxies []ProxyStats
+	if s.proxies != nil {
+		proxies = s.proxies.Stats()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.stats
+	report := CrawlReport{
+		Source:       s.Source,
+		BaseURL:      s.BaseURL,
+		StartedAt:    st.startedAt,
+		FinishedAt:   st.finishedAt,
+		Duration:     st.finishedAt.Sub(st.startedAt).Seconds(),
+		Pages:        len(s.Pages),
+		Requests:     st.requests,
+		Bytes:        st.bytes,
+		StatusCodes:  st.status,
+		ContentTypes: make(map[string]int),
+		Depths:       st.depths,
+		ResponseTime: responseTimes(st.durations),
+		SkipReasons:  make(map[string]int),
+		Skipped:      []SkippedURL{},
+		Failed:       failed,
+		Duplicates:   dedup,
+		Proxies:      proxies,
+	}
+	for _, page := range s.Pages {
+		report.ContentTypes[page.ContentType]++
+	}
+	for u, reason := range st.skipped {
+		report.Skipped = append(report.Skipped, SkippedURL{URL: u, Reason: reason})
+		report.SkipReasons[reason]++
+	}
+	sort.Slice(report.Skipped, func(i, j int) bool { return report.Skipped[i].URL < report.Skipped[j].URL })
+
+	return report
+}
+
+// responseTimes считает среднее и перцентили времени ответа
+func responseTimes(durations []time.Duration) ResponseTimes {
+	if len(durations) == 0 {
+		return ResponseTimes{}
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	percentile := func(p float64) int64 {
+		return sorted[int(p*float64(len(sorted)-1))].Milliseconds()
+	}
+	return ResponseTimes{
+		Avg: (total / time.Duration(len(sorted))).Milliseconds(),
+		P50: percentile(0.5),
+		P95: percentile(0.95),
+		Max: sorted[len(sorted)-1].Milliseconds(),
+	}
+}
+
+// MergeLinkGraphs объединяет графы нескольких источников
+func MergeLinkGraphs(graphs []LinkGraph) LinkGraph {
+	merged := LinkGraph{Nodes: []GraphNode{}, Edges: []LinkEdge{}}
+	for _, g := range graphs {
+		merged.Nodes = append(merged.Nodes, g.Nodes...)
+		merged.Edges = append(merged.Edges, g.Edges...)
+	}
+	return merged
+}
+
+// SaveCrawlReport сохраняет отчеты об обходе в JSON файл
+func SaveCrawlReport(reports []CrawlReport, filename string) error {
+	data, err := json.MarshalIndent(reports, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации: %w", err)
+	}
+
+	err = os.WriteFile(filename, data, 0644)
+	if err != nil {
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+
+	log.Printf("Отчет об обходе сохранен в %s", filename)
+	return nil
+}
+
+// SaveLinkGraph сохраняет граф ссылок: .dot — в формате Graphviz, иначе в JSON
+func SaveLinkGraph(graph LinkGraph, filename string) error {
+	var data []byte
+	if strings.HasSuffix(strings.ToLower(filename), ".dot") {
+		data = []byte(graph.DOT())
+	} else {
+		var err error
+		data, err = json.MarshalIndent(graph, "", "  ")
+		if err != nil {
+			return fmt.Errorf("ошибка сериализации: %w", err)
+		}
+	}
+
+	err := os.WriteFile(filename, data, 0644)
+	if err != nil {
+		return fmt.Errorf("ошибка записи файла: %w", err)
+	}
+
+	log.Printf("Граф ссылок сохранен в %s (страниц: %d, ссылок: %d)", filename, len(graph.Nodes), len(graph.Edges))
+	return nil
+}
+
+// DOT возвращает граф в формате Graphviz. Несобранные страницы выделены
+// пунктиром, несколько ссылок между парой страниц объединяются в одно ребро.
+func (g LinkGraph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph links {\n")
+	sb.WriteString("  node [shape=box, fontsize=10];\n")
+	for _, n := range g.Nodes {
+		label := n.Title
+		if label == "" {
+			label = n.URL
+		}
+		style := ""
+		if !n.Crawled {
+			style = ", style=dashed, color=gray"
+		}
+		fmt.Fprintf(&sb, "  %s [label=%s, tooltip=%s%s];\n", dotQuote(n.URL), dotQuote(label), dotQuote(n.URL), style)
+	}
+
+	type pair struct{ from, to string }
+	anchors := make(map[pair][]string)
+	var order []pair
+	for _, e := range g.Edges {
+		p := pair{e.From, e.To}
+		if _, ok := anchors[p]; !ok {
+			order = append(order, p)
+			anchors[p] = nil
+		}
+		if e.Anchor != "" {
+			anchors[p] = append(anchors[p], e.Anchor)
+		}
+	}
+	for _, p := range order {
+		fmt.Fprintf(&sb, "  %s -> %s", dotQuote(p.from), dotQuote(p.to))
+		if labels := anchors[p]; len(labels) > 0 {
+			fmt.Fprintf(&sb, " [label=%s]", dotQuote(strings.Join(labels, " / ")))
+		}
+		sb.WriteString(";\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// dotQuote экранирует строку для DOT
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ").Replace(s) + `"`
+}
@@ -0,0 +1,129 @@
+package scraper
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// graphScraper скрапер с собранными страницами и ссылками, где встречаются
+// варианты адресов со слешем и адрес дубликата
+func graphScraper() *Scraper {
+	const base = "https://example.com"
+	s := NewScraper(base+"/", 10, 0)
+	s.Pages = []PageData{
+		{URL: base + "/", Title: "Главная", ContentType: ContentTypeHTML},
+		{URL: base + "/courses", Title: "Курсы", ContentType: ContentTypeHTML, Duplicates: []string{base + "/courses?page=1"}},
+		{URL: base + "/rules.pdf", Title: "Правила", ContentType: ContentTypePDF},
+	}
+	s.recordEdge(base+"/", base+"/courses/", "Курсы")
+	s.recordEdge(base+"/", base+"/courses?page=1", " Курсы ")
+	s.recordEdge(base+"/", base+"/courses?page=1", "Все курсы")
+	s.recordEdge(base+"/courses?page=1", base+"/", "Главная")
+	s.recordEdge(base+"/courses/", base+"/courses?page=1", "")
+	s.recordEdge(base+"/", base+"/about", "О нас")
+	s.recordEdge(base+"/courses", base+"/about/", "О нас")
+	s.recordEdge(base+"/", base+"/rules.pdf", "Правила приема")
+	return s
+}
+
+func TestLinkGraph(t *testing.T) {
+	const base = "https://example.com"
+	graph := graphScraper().LinkGraph()
+
+	wantNodes := []GraphNode{
+		{URL: base + "/", Title: "Главная", Crawled: true, InLinks: 1, OutLinks: 4},
+		{URL: base + "/about", InLinks: 2},
+		{URL: base + "/courses", Title: "Курсы", Crawled: true, InLinks: 2, OutLinks: 2},
+		{URL: base + "/rules.pdf", Title: "Правила", Crawled: true, InLinks: 1},
+	}
+	if !slices.Equal(graph.Nodes, wantNodes) {
+		t.Errorf("узлы:\n%+v\nwant:\n%+v", graph.Nodes, wantNodes)
+	}
+
+	// Слеш-варианты и дубликат сведены к узлам, одинаковые ссылки объединены,
+	// ссылка дубликата на свою страницу отброшена
+	wantEdges := []LinkEdge{
+		{From: base + "/", To: base + "/about", Anchor: "О нас"},
+		{From: base + "/", To: base + "/courses", Anchor: "Все курсы"},
+		{From: base + "/", To: base + "/courses", Anchor: "Курсы"},
+		{From: base + "/", To: base + "/rules.pdf", Anchor: "Правила приема"},
+		{From: base + "/courses", To: base + "/", Anchor: "Главная"},
+		{From: base + "/courses", To: base + "/about", Anchor: "О нас"},
+	}
+	if !slices.Equal(graph.Edges, wantEdges) {
+		t.Errorf("ссылки:\n%+v\nwant:\n%+v", graph.Edges, wantEdges)
+	}
+}
+
+func TestLinkGraphDOT(t *testing.T) {
+	want := `digraph links {
+  node [shape=box, fontsize=10];
+  "https://example.com/" [label="Главная", tooltip="https://example.com/"];
+  "https://example.com/about" [label="https://example.com/about", tooltip="https://example.com/about", style=dashed, color=gray];
+  "https://example.com/courses" [label="Курсы", tooltip="https://example.com/courses"];
+  "https://example.com/rules.pdf" [label="Правила", tooltip="https://example.com/rules.pdf"];
+  "https://example.com/" -> "https://example.com/about" [label="О нас"];
+  "https://example.com/" -> "https://example.com/courses" [label="Все курсы / Курсы"];
+  "https://example.com/" -> "https://example.com/rules.pdf" [label="Правила приема"];
+  "https://example.com/courses" -> "https://example.com/" [label="Главная"];
+  "https://example.com/courses" -> "https://example.com/about" [label="О нас"];
+}
+`
+	if got := graphScraper().LinkGraph().DOT(); got != want {
+		t.Errorf("DOT:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Каждый конец ребра объявлен как узел
+	graph := graphScraper().LinkGraph()
+	declared := make(map[string]bool)
+	for _, n := range graph.Nodes {
+		declared[n.URL] = true
+	}
+	for _, e := range graph.Edges {
+		if !declared[e.From] || !declared[e.To] {
+			t.Errorf("ребро %s -> %s ведет к необъявленному узлу", e.From, e.To)
+		}
+	}
+
+	if q := dotQuote("a \"b\"\\\nc"); q != `"a \"b\"\\ c"` {
+		t.Errorf("dotQuote = %s", q)
+	}
+}
+
+func TestReport(t *testing.T) {
+	s := graphScraper()
+	s.Source = "sop"
+	s.stats.startedAt = time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	s.stats.finishedAt = s.stats.startedAt.Add(90 * time.Second)
+	s.stats.requests = 5
+	s.stats.status[200] = 4
+	s.stats.status[404] = 1
+	s.stats.durations = []time.Duration{40 * time.Millisecond, 10 * time.Millisecond, 30 * time.Millisecond, 20 * time.Millisecond}
+	s.recordSkip("https://example.com/photo.jpg", SkipFileType)
+	s.recordSkip("https://example.com/admin", SkipRobots)
+	s.recordSkip("https://example.com/empty", SkipNoText)
+	s.recordSkip("https://example.com/private", SkipRobots)
+	s.failed[s.key("https://example.com/broken")] = FailedURL{URL: "https://example.com/broken", Status: 500, Attempts: 4}
+
+	report := s.Report(DedupStats{NearDuplicates: 2})
+	if report.Source != "sop" || report.Pages != 3 || report.Requests != 5 || report.Duration != 90 {
+		t.Errorf("отчет: source %q, страниц %d, запросов %d, %v с", report.Source, report.Pages, report.Requests, report.Duration)
+	}
+	if report.ContentTypes[ContentTypeHTML] != 2 || report.ContentTypes[ContentTypePDF] != 1 {
+		t.Errorf("ContentTypes = %v", report.ContentTypes)
+	}
+	if report.SkipReasons[SkipRobots] != 2 || report.SkipReasons[SkipFileType] != 1 || len(report.Skipped) != 4 {
+		t.Errorf("пропуски: %v, %v", report.SkipReasons, report.Skipped)
+	}
+	if !slices.IsSortedFunc(report.Skipped, func(a, b SkippedURL) int { return strings.Compare(a.URL, b.URL) }) {
+		t.Errorf("Skipped не отсортирован: %v", report.Skipped)
+	}
+	if want := (ResponseTimes{Avg: 25, P50: 20, P95: 30, Max: 40}); report.ResponseTime != want {
+		t.Errorf("ResponseTime = %+v, want %+v", report.ResponseTime, want)
+	}
+	if len(report.Failed) != 1 || report.Failed[0].Status != 500 || report.Duplicates.NearDuplicates != 2 {
+		t.Errorf("Failed = %+v, Duplicates = %+v", report.Failed, report.Duplicates)
+	}
+}
@@ -0,0 +1,297 @@
+package scraper
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Типы блоков раздела
+const (
+	BlockParagraph = "paragraph"
+	BlockList      = "list"
+	BlockTable     = "table"
+)
+
+// Block элемент содержимого раздела: абзац, список или таблица
+type Block struct {
+	Type    string     `json:"type"`
+	Text    string     `json:"text,omitempty"`
+	Items   []string   `json:"items,omitempty"`
+	Ordered bool       `json:"ordered,omitempty"`
+	Rows    [][]string `json:"rows,omitempty"`
+}
+
+// Section раздел страницы под заголовком.
+// Path содержит цепочку заголовков от корня страницы до раздела включительно.
+type Section struct {
+	Heading  string    `json:"heading,omitempty"`
+	Level    int       `json:"level"`
+	Path     []string  `json:"path,omitempty"`
+	Blocks   []Block   `json:"blocks,omitempty"`
+	Children []Section `json:"children,omitempty"`
+}
+
+// sectionBuilder собирает дерево разделов при обходе DOM
+type sectionBuilder struct {
+	root  Section
+	stack []*Section
+	para  strings.Builder
+}
+
+func newSectionBuilder() *sectionBuilder {
+	b := &sectionBuilder{}
+	b.stack = []*Section{&b.root}
+	return b
+}
+
+// current возвращает раздел, в который добавляются блоки
+func (b *sectionBuilder) current() *Section {
+	return b.stack[len(b.stack)-1]
+}
+
+// flush завершает накопленный абзац
+func (b *sectionBuilder) flush() {
+	text := strings.Join(strings.Fields(b.para.String()), " ")
+	b.para.Reset()
+	if text != "" {
+		b.addBlock(Block{Type: BlockParagraph, Text: text})
+	}
+}
+
+func (b *sectionBuilder) addBlock(block Block) {
+	cur := b.current()
+	cur.Blocks = append(cur.Blocks, block)
+}
+
+// openSection начинает раздел уровня level, закрывая разделы того же или более глубокого уровня
+func (b *sectionBuilder) openSection(level int, heading string) {
+	b.flush()
+	for len(b.stack) > 1 && b.current().Level >= level {
+		b.stack = b.stack[:len(b.stack)-1]
+	}
+
+	parent := b.current()
+	path := append(append([]string(nil), parent.Path...), heading)
+	parent.Children = append(parent.Children, Section{Heading: heading, Level: level, Path: path})
+	b.stack = append(b.stack, &parent.Children[len(parent.Children)-1])
+}
+
+// sections возвращает разделы верхнего уровня; текст до первого заголовка
+// попадает в раздел без заголовка (уровень 0)
+func (b *sectionBuilder) sections() []Section {
+	b.flush()
+	var result []Section
+	if len(b.root.Blocks) > 0 {
+		result = append(result, Section{Blocks: b.root.Blocks})
+	}
+	return append(result, b.root.Children...)
+}
+
+// buildSections строит дерево разделов по узлам основного контента
+func buildSections(nodes []*html.Node) []Section {
+	b := newSectionBuilder()
+	for _, n := range nodes {
+		b.walk(n)
+	}
+	return b.sections()
+}
+
+func (b *sectionBuilder) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		b.para.WriteString(n.Data)
+		return
+	case html.ElementNode:
+	case html.DocumentNode:
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			b.walk(c)
+		}
+		return
+	default:
+		return
+	}
+
+	switch n.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		if heading := innerText(n); heading != "" {
+			b.openSection(int(n.Data[1]-'0'), heading)
+		}
+		return
+	case "ul", "ol":
+		b.flush()
+		if items := listItems(n, ""); len(items) > 0 {
+			b.addBlock(Block{Type: BlockList, Items: items, Ordered: n.Data == "ol"})
+		}
+		return
+	case "table":
+		b.flush()
+		if rows := tableRows(n); len(rows) > 0 {
+			b.addBlock(Block{Type: BlockTable, Rows: rows})
+		}
+		return
+	case "img":
+		if alt := attr(n, "alt"); alt != "" {
+			b.para.WriteString(" " + alt + " ")
+		}
+		return
+	}
+
+	if blockTags[n.Data] {
+		b.flush()
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.walk(c)
+	}
+	if blockTags[n.Data] {
+		b.flush()
+	}
+}
+
+// listItems собирает пункты списка; вложенные списки идут с отступом
+func listItems(list *html.Node, indent string) []string {
+	var items []string
+	for li := list.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.Data != "li" {
+			continue
+		}
+
+		var own strings.Builder
+		var nested []string
+		for c := li.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && (c.Data == "ul" || c.Data == "ol") {
+				nested = append(nested, listItems(c, indent+"  ")...)
+				continue
+			}
+			if c.Type == html.TextNode {
+				own.WriteString(c.Data)
+			} else {
+				own.WriteString(" " + innerText(c) + " ")
+			}
+		}
+
+		if text := strings.Join(strings.Fields(own.String()), " "); text != "" {
+			items = append(items, indent+text)
+		}
+		items = append(items, nested...)
+	}
+	return items
+}
+
+// tableRows собирает строки таблицы (включая thead/tbody/tfoot)
+func tableRows(table *html.Node) [][]string {
+	var rows [][]string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.Data {
+			case "tr":
+				var cells []string
+				for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.Data == "td" || cell.Data == "th") {
+						cells = append(cells, innerText(cell))
+					}
+				}
+				if strings.Join(cells, "") != "" {
+					rows = append(rows, cells)
+				}
+			case "table":
+				// вложенные таблицы разбираются отдельно не будут
+			default:
+				walk(c)
+			}
+		}
+	}
+	walk(table)
+	return rows
+}
+
+// SectionsMarkdown сериализует дерево разделов в Markdown
+func SectionsMarkdown(sections []Section) string {
+	var sb strings.Builder
+	for _, section := range sections {
+		writeSectionMarkdown(&sb, section)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// Markdown возвращает Markdown представление страницы; для страниц
+// без разделов (документы, старые данные) возвращается обычный текст
+func (p PageData) Markdown() string {
+	if len(p.Sections) == 0 {
+		return p.Text
+	}
+	return SectionsMarkdown(p.Sections)
+}
+
+func writeSectionMarkdown(sb *strings.Builder, section Section) {
+	if section.Heading != "" {
+		level := min(max(section.Level, 1), 6)
+		sb.WriteString(strings.Repeat("#", level) + " " + section.Heading + "\n\n")
+	}
+	for _, block := range section.Blocks {
+		sb.WriteString(BlockMarkdown(block))
+		sb.WriteString("\n\n")
+	}
+	for _, child := range section.Children {
+		writeSectionMarkdown(sb, child)
+	}
+}
+
+// BlockMarkdown сериализует один блок в Markdown
+func BlockMarkdown(block Block) string {
+	switch block.Type {
+	case BlockList:
+		lines := make([]string, len(block.Items))
+		num := 0
+		for i, item := range block.Items {
+			indent := item[:len(item)-len(strings.TrimLeft(item, " "))]
+			marker := "- "
+			if block.Ordered && indent == "" {
+				num++
+				marker = fmt.Sprintf("%d. ", num)
+			}
+			lines[i] = indent + marker + strings.TrimLeft(item, " ")
+		}
+		return strings.Join(lines, "\n")
+	case BlockTable:
+		return tableMarkdown(block.Rows)
+	}
+	return block.Text
+}
+
+// tableMarkdown сериализует таблицу; первая строка считается заголовком
+func tableMarkdown(rows [][]string) string {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	if width == 0 {
+		return ""
+	}
+
+	line := func(cells []string) string {
+		padded := make([]string, width)
+		for i := range padded {
+			if i < len(cells) {
+				padded[i] = strings.ReplaceAll(cells[i], "|", `\|`)
+			}
+		}
+		return "| " + strings.Join(padded, " | ") + " |"
+	}
+
+	lines := []string{line(rows[0])}
+	sep := make([]string, width)
+	for i := range sep {
+		sep[i] = "---"
+	}
+	lines = append(lines, line(sep))
+	for _, row := range rows[1:] {
+		lines = append(lines, line(row))
+	}
+	return strings.Join(lines, "\n")
+}
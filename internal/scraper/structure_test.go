@@ -0,0 +1,125 @@
+package scraper
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// sectionsOf строит дерево разделов по телу HTML фрагмента
+func sectionsOf(t *testing.T, body string) []Section {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><body>" + body + "</body></html>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buildSections(doc.Find("body").Nodes)
+}
+
+func TestSectionsMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "вложенные заголовки",
+			html: `<p>Вступление до заголовков.</p>
+<h1>Курсы</h1><p>Все курсы центра.</p>
+<h2>Машинисты</h2><p>Шесть месяцев.</p>
+<h3>Стажировка</h3><p>В депо.</p>
+<h2>Диспетчеры</h2><p>Три месяца.</p>
+<h1>Контакты</h1><p>Телефон.</p>`,
+			want: `Вступление до заголовков.
+
+# Курсы
+
+Все курсы центра.
+
+## Машинисты
+
+Шесть месяцев.
+
+### Стажировка
+
+В депо.
+
+## Диспетчеры
+
+Три месяца.
+
+# Контакты
+
+Телефон.`,
+		},
+		{
+			name: "пропуск уровня",
+			html: `<h2>Раздел</h2><h4>Подраздел</h4><p>Текст.</p><h3>Соседний</h3><p>Еще.</p>`,
+			want: `## Раздел
+
+#### Подраздел
+
+Текст.
+
+### Соседний
+
+Еще.`,
+		},
+		{
+			name: "списки",
+			html: `<h2>Документы</h2>
+<ol><li>Паспорт</li><li>Диплом<ul><li>копия</li><li><b>оригинал</b> при зачислении</li></ul></li><li>Фото</li></ol>
+<ul><li>Очно</li><li></li><li>Заочно</li></ul>`,
+			want: `## Документы
+
+1. Паспорт
+2. Диплом
+  - копия
+  - оригинал при зачислении
+3. Фото
+
+- Очно
+- Заочно`,
+		},
+		{
+			name: "таблицы",
+			html: `<table><thead><tr><th>Курс</th><th>Часы</th></tr></thead>
+<tbody><tr><td>Охрана труда</td><td>72</td></tr><tr><td>Связь | СЦБ</td></tr><tr><td></td><td></td></tr></tbody></table>`,
+			want: `| Курс | Часы |
+| --- | --- |
+| Охрана труда | 72 |
+| Связь \| СЦБ |  |`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SectionsMarkdown(sectionsOf(t, tt.html))
+			if got != tt.want {
+				t.Errorf("SectionsMarkdown:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSectionsPath(t *testing.T) {
+	sections := sectionsOf(t, `<h1>Курсы</h1><h2>Машинисты</h2><h3>Стажировка</h3><p>В депо.</p>`)
+	if len(sections) != 1 {
+		t.Fatalf("разделов верхнего уровня %d, want 1", len(sections))
+	}
+	leaf := sections[0].Children[0].Children[0]
+	if want := []string{"Курсы", "Машинисты", "Стажировка"}; !slices.Equal(leaf.Path, want) {
+		t.Errorf("Path = %v, want %v", leaf.Path, want)
+	}
+	if len(leaf.Blocks) != 1 || leaf.Blocks[0].Text != "В депо." {
+		t.Errorf("Blocks = %+v", leaf.Blocks)
+	}
+}
+
+func TestPageMarkdownWithoutSections(t *testing.T) {
+	page := PageData{Text: "Текст документа"}
+	if got := page.Markdown(); got != page.Text {
+		t.Errorf("Markdown = %q, want текст страницы", got)
+	}
+}
@@ -0,0 +1,327 @@
+package scraper
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// warcVersion версия формата WARC (ISO 28500:2017)
+const warcVersion = "WARC/1.1"
+
+// Типы записей WARC
+const (
+	WARCInfo     = "warcinfo"
+	WARCResponse = "response"
+)
+
+// WARCRecord одна запись WARC файла
+type WARCRecord struct {
+	Type      string
+	TargetURI string
+	Date      time.Time
+	Header    textproto.MIMEHeader
+	Block     []byte
+}
+
+// WARCWriter записывает HTTP ответы в WARC файл.
+// Файлы с расширением .gz сжимаются по записям, как принято для .warc.gz.
+type WARCWriter struct {
+	mu      sync.Mutex
+	file    *os.File
+	gzip    bool
+	records int
+}
+
+// NewWARCWriter создает WARC файл и записывает в него запись warcinfo
+func NewWARCWriter(filename string) (*WARCWriter, error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания файла: %w", err)
+	}
+
+	w := &WARCWriter{file: file, gzip: strings.HasSuffix(filename, ".gz")}
+	info := "software: DriveHack scraper\r\nformat: WARC File Format 1.1\r\n"
+	if err := w.write(WARCInfo, "", "application/warc-fields", []byte(info)); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// WriteResponse записывает ответ сервера: статусную строку, заголовки и тело
+func (w *WARCWriter) WriteResponse(targetURI string, resp *http.Response, body []byte) error {
+	var block bytes.Buffer
+	fmt.Fprintf(&block, "%s %s\r\n", resp.Proto, resp.Status)
+
+	// Chunked кодирование уже снято транспортом, поэтому длину указываем заново
+	header := resp.Header.Clone()
+	header.Del("Transfer-Encoding")
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	header.Write(&block)
+	block.WriteString("\r\n")
+	block.Write(body)
+
+	return w.write(WARCResponse, targetURI, "application/http;msgtype=response", block.Bytes())
+}
+
+// write добавляет запись в файл
+func (w *WARCWriter) write(recordType, targetURI, contentType string, block []byte) error {
+	id, err := recordID()
+	if err != nil {
+		return err
+	}
+	digest := sha1.Sum(block)
+
+	var record bytes.Buffer
+	record.WriteString(warcVersion + "\r\n")
+	fmt.Fprintf(&record, "WARC-Type: %s\r\n", recordType)
+	fmt.Fprintf(&record, "WARC-Record-ID: <urn:uuid:%s>\r\n", id)
+	fmt.Fprintf(&record, "WARC-Date: %s\r\n", time.Now().UTC().Format(time.RFC3339))
+	if targetURI != "" {
+		fmt.Fprintf(&record, "WARC-Target-URI: %s\r\n", targetURI)
+	}
+	fmt.Fprintf(&record, "WARC-Block-Digest: sha1:%s\r\n", base32.StdEncoding.EncodeToString(digest[:]))
+	fmt.Fprintf(&record, "Content-Type: %s\r\n", contentType)
+	fmt.Fprintf(&record, "Content-Length: %d\r\n", len(block))
+	record.WriteString("\r\n")
+	record.Write(block)
+	record.WriteString("\r\n\r\n")
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.gzip {
+		gz := gzip.NewWriter(w.file)
+		if _, err := gz.Write(record.Bytes()); err != nil {
+			return fmt.Errorf("ошибка записи WARC: %w", err)
+		}
+		if err := gz.Close(); err != nil {
+			return fmt.Errorf("ошибка записи WARC: %w", err)
+		}
+	} else if _, err := w.file.Write(record.Bytes()); err != nil {
+		return fmt.Errorf("ошибка записи WARC: %w", err)
+	}
+	w.records++
+	return nil
+}
+
+// Close закрывает WARC файл
+func (w *WARCWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	log.Printf("WARC: записано %d записей в %s", w.records, w.file.Name())
+	return w.file.Close()
+}
+
+// recordID генерирует случайный UUID v4 для WARC-Record-ID
+func recordID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("ошибка генерации идентификатора: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// ReadWARC последовательно читает записи WARC файла (в том числе .warc.gz)
+func ReadWARC(filename string, fn func(WARCRecord) error) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(filename, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("ошибка распаковки WARC: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	reader := textproto.NewReader(bufio.NewReader(r))
+	for {
+		record, err := readWARCRecord(reader)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+// readWARCRecord читает одну запись: версию, заголовки и блок
+func readWARCRecord(reader *textproto.Reader) (WARCRecord, error) {
+	// Пропускаем пустые строки между записями
+	var version string
+	for version == "" {
+		line, err := reader.ReadLine()
+		if err != nil {
+			return WARCRecord{}, err
+		}
+		version = strings.TrimSpace(line)
+	}
+	if !strings.HasPrefix(version, "WARC/") {
+		return WARCRecord{}, fmt.Errorf("некорректная запись WARC: %q", version)
+	}
+
+	header, err := reader.ReadMIMEHeader()
+	if err != nil {
+		return WARCRecord{}, fmt.Errorf("ошибка чтения заголовков WARC: %w", err)
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return WARCRecord{}, fmt.Errorf("некорректный Content-Length записи WARC: %q", header.Get("Content-Length"))
+	}
+
+	block := make([]byte, length)
+	if _, err := io.ReadFull(reader.R, block); err != nil {
+		return WARCRecord{}, fmt.Errorf("ошибка чтения записи WARC: %w", err)
+	}
+
+	date, _ := time.Parse(time.RFC3339, header.Get("WARC-Date"))
+	return WARCRecord{
+		Type:      header.Get("WARC-Type"),
+		TargetURI: strings.Trim(header.Get("WARC-Target-URI"), "<>"),
+		Date:      date,
+		Header:    header,
+		Block:     block,
+	}, nil
+}
+
+// warcTransport сохраняет в WARC каждый полученный ответ
+type warcTransport struct {
+	base    http.RoundTripper
+	writer  *WARCWriter
+	maxBody int
+}
+
+// RoundTrip выполняет запрос и записывает ответ в архив
+func (t *warcTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(t.maxBody)+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	// Слишком большой ответ не архивируем, остаток тела отдаем как есть
+	if len(body) > t.maxBody {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := t.writer.WriteResponse(req.URL.String(), resp, body); err != nil {
+		log.Printf("Ошибка записи в WARC %s: %v", req.URL, err)
+	}
+	return resp, nil
+}
+
+// WARCArchive ответы из WARC файла для обхода без сети
+type WARCArchive struct {
+	responses map[string][]byte
+}
+
+// LoadWARC загружает ответы из WARC файла. Для повторяющихся URL берется
+// последний полный ответ; ответы 304 не заменяют сохраненную страницу.
+func LoadWARC(filename string) (*WARCArchive, error) {
+	a := &WARCArchive{responses: make(map[string][]byte)}
+	err := ReadWARC(filename, func(record WARCRecord) error {
+		if record.Type != WARCResponse || record.TargetURI == "" {
+			return nil
+		}
+		if bytes.HasPrefix(record.Block, []byte("HTTP/")) && statusCode(record.Block) == http.StatusNotModified {
+			return nil
+		}
+		a.responses[urlKey(record.TargetURI, "")] = record.Block
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Загружен WARC %s: %d ответов", filename, len(a.responses))
+	return a, nil
+}
+
+// statusCode возвращает код ответа из статусной строки HTTP
+func statusCode(block []byte) int {
+	line, _, _ := bytes.Cut(block, []byte("\n"))
+	fields := strings.Fields(string(line))
+	if len(fields) < 2 {
+		return 0
+	}
+	code, _ := strconv.Atoi(fields[1])
+	return code
+}
+
+// RoundTrip отдает сохраненный ответ или 404, если URL нет в архиве
+func (a *WARCArchive) RoundTrip(req *http.Request) (*http.Response, error) {
+	raw, ok := a.responses[urlKey(req.URL.String(), "")]
+	if !ok {
+		return &http.Response{
+			Status:     "404 Not Found",
+			StatusCode: http.StatusNotFound,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     make(http.Header),
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(raw)), req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка разбора ответа из WARC для %s: %w", req.URL, err)
+	}
+	return resp, nil
+}
+
+// SetWARC включает запись всех ответов в WARC
+func (s *Scraper) SetWARC(w *WARCWriter) {
+	s.warc = w
+	s.applyTransport()
+}
+
+// SetReplay переключает скрапер на ответы из WARC архива: сеть не используется,
+// задержки между запросами не нужны
+func (s *Scraper) SetReplay(a *WARCArchive) {
+	s.replay = a
+	s.applyTransport()
+
+	s.delay = 0
+	s.limit.Delay = 0
+	s.limit.RandomDelay = 0
+}
@@ -0,0 +1,81 @@
+package scraper
+
+import (
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWARCReplay(t *testing.T) {
+	site := fixtureSite(t, 5)
+	robots := http.NewServeMux()
+	robots.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("User-agent: *\nDisallow: /page4\nCrawl-delay: 5\n"))
+	})
+	robots.Handle("/", site.Config.Handler)
+	site.Config.Handler = robots
+	defer site.Close()
+
+	// Живой обход с записью всех ответов в архив
+	file := filepath.Join(t.TempDir(), "crawl.warc.gz")
+	writer, err := NewWARCWriter(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	live := NewScraper(site.URL+"/", 100, 0)
+	live.MaxRetries = 0
+	live.RetryDelay = time.Millisecond
+	live.SetWARC(writer)
+	if _, err := live.LoadRobots(); err != nil {
+		t.Fatal(err)
+	}
+	// Тесту незачем ждать Crawl-delay живого сервера
+	live.limit.Delay = 0
+	live.Crawl(site.URL + "/")
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	site.Close()
+
+	// Повтор без сети: те же страницы и без задержек из robots.txt
+	archive, err := LoadWARC(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay := NewScraper(site.URL+"/", 100, time.Second)
+	replay.MaxRetries = 0
+	replay.RetryDelay = time.Millisecond
+	replay.SetReplay(archive)
+	if _, err := replay.LoadRobots(); err != nil {
+		t.Fatal(err)
+	}
+	if replay.limit.Delay != 0 {
+		t.Errorf("при чтении из WARC применена задержка %v", replay.limit.Delay)
+	}
+	start := time.Now()
+	replay.Crawl(site.URL + "/")
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("обход из WARC занял %v", elapsed)
+	}
+
+	if len(live.Pages) == 0 {
+		t.Fatal("живой обход не собрал страниц")
+	}
+	for _, page := range live.Pages {
+		if page.URL == site.URL+"/page4" {
+			t.Error("страница, запрещенная robots.txt, собрана")
+		}
+	}
+	if len(replay.Pages) != len(live.Pages) {
+		t.Fatalf("из WARC собрано %d страниц, при обходе %d", len(replay.Pages), len(live.Pages))
+	}
+	for i := range live.Pages {
+		want, got := live.Pages[i], replay.Pages[i]
+		want.FetchedAt, got.FetchedAt = "", ""
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("страница %d из WARC отличается:\n got %+v\nwant %+v", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// TokenFilter преобразует токен; пустая строка — токен отбрасывается
+type TokenFilter func(token string) string
+
+// Analyzer превращает текст в термы индекса: токенизация и цепочка фильтров.
+// Документы и запросы должны проходить через один и тот же анализатор.
+type Analyzer struct {
+	Tokenize func(text string) []string
+	Filters  []TokenFilter
+}
+
+// NewAnalyzer создает анализатор с токенизацией по буквам и цифрам
+func NewAnalyzer(filters ...TokenFilter) *Analyzer {
+	return &Analyzer{Tokenize: tokenize, Filters: filters}
+}
+
+// NewRussianAnalyzer анализатор по умолчанию: нижний регистр, ё -> е,
+// русские и английские стоп-слова, стемминг Snowball для русских слов
+func NewRussianAnalyzer() *Analyzer {
+	return NewAnalyzer(LowercaseFilter, YoFilter, StopWordFilter(russianStopWords, englishStopWords), RussianStemFilter)
+}
+
+// Analyze возвращает термы текста
+func (a *Analyzer) Analyze(text string) []string {
+	tokens := a.Tokenize(text)
+	result := tokens[:0]
+	for _, token := range tokens {
+		for _, filter := range a.Filters {
+			if token = filter(token); token == "" {
+				break
+			}
+		}
+		if token != "" {
+			result = append(result, token)
+		}
+	}
+	return result
+}
+
+// tokenize разбивает текст на последовательности букв и цифр любого алфавита
+func tokenize(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// LowercaseFilter приводит токен к нижнему регистру
+func LowercaseFilter(token string) string {
+	return strings.ToLower(token)
+}
+
+// YoFilter заменяет "ё" на "е": в текстах сайтов они пишутся вперемешку
+func YoFilter(token string) string {
+	return strings.ReplaceAll(token, "ё", "е")
+}
+
+// StopWordFilter отбрасывает слова из списков (в нижнем регистре, с "е" вместо "ё")
+func StopWordFilter(lists ...[]string) TokenFilter {
+	stop := make(map[string]bool)
+	for _, list := range lists {
+		for _, word := range list {
+			stop[word] = true
+		}
+	}
+	return func(token string) string {
+		if stop[token] {
+			return ""
+		}
+		return token
+	}
+}
+
+// RussianStemFilter оставляет основу русского слова; остальные токены не меняются
+func RussianStemFilter(token string) string {
+	return StemRussian(token)
+}
+
+// russianStopWords служебные слова русского языка (список Snowball)
+var russianStopWords = []string{
+	"и", "в", "во", "не", "что", "он", "на", "я", "с", "со", "как", "а", "то", "все", "она", "так", "его",
+	"но", "да", "ты", "к", "у", "же", "вы", "за", "бы", "по", "только", "ее", "мне", "было", "вот", "от",
+	"меня", "еще", "нет", "о", "из", "ему", "теперь", "когда", "даже", "ну", "вдруг", "ли", "если", "уже",
+	"или", "ни", "быть", "был", "него", "до", "вас", "нибудь", "опять", "уж", "вам", "ведь", "там", "потом",
+	"себя", "ничего", "ей", "может", "они", "тут", "где", "есть", "надо", "ней", "для", "мы", "тебя", "их",
+	"чем", "была", "сам", "чтоб", "без", "будто", "чего", "раз", "тоже", "себе", "под", "будет", "ж", "тогда",
+	"кто", "этот", "того", "потому", "этого", "какой", "совсем", "ним", "здесь", "этом", "один", "почти",
+	"мой", "тем", "чтобы", "нее", "сейчас", "были", "куда", "зачем", "всех", "никогда", "можно", "при",
+	"наконец", "два", "об", "другой", "хоть", "после", "над", "больше", "тот", "через", "эти", "нас", "про",
+	"всего", "них", "какая", "много", "разве", "три", "эту", "моя", "впрочем", "хорошо", "свою", "этой",
+	"перед", "иногда", "лучше", "чуть", "том", "нельзя", "такой", "им", "более", "всегда", "конечно", "всю",
+	"между",
+}
+
+// englishStopWords служебные слова английского языка
+var englishStopWords = []string{
+	"a", "an", "and", "are", "as", "at", "be", "but", "by", "for", "if", "in", "into", "is", "it", "no",
+	"not", "of", "on", "or", "such", "that", "the", "their", "then", "there", "these", "they", "this", "to",
+	"was", "will", "with",
+}
@@ -0,0 +1,40 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRussianAnalyzer(t *testing.T) {
+	a := NewRussianAnalyzer()
+	tests := []struct {
+		text string
+		want []string
+	}{
+		// Служебные слова обоих языков отбрасываются, остальные приводятся к основе
+		{"Какие программы и курсы есть для машинистов в 2024 году?", []string{"как", "программ", "курс", "машинист", "2024", "год"}},
+		{"The best of training", []string{"best", "training"}},
+		{"Ёлки, ЕЛКИ и ёлки-палки", []string{"елк", "елк", "елк", "палк"}},
+		{"и в на по с", nil},
+	}
+	for _, tt := range tests {
+		if got := a.Analyze(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("Analyze(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestStopWordFilter(t *testing.T) {
+	filter := StopWordFilter([]string{"и", "еще"}, []string{"the"})
+	for token, want := range map[string]string{"и": "", "еще": "", "the": "", "курс": "курс", "ещё": "ещё"} {
+		if got := filter(token); got != want {
+			t.Errorf("filter(%q) = %q, want %q", token, got, want)
+		}
+	}
+
+	// Без фильтра стоп-слов служебные слова остаются
+	plain := NewAnalyzer(LowercaseFilter)
+	if got := plain.Analyze("Курсы и Программы"); !slices.Equal(got, []string{"курсы", "и", "программы"}) {
+		t.Errorf("Analyze без стоп-слов = %q", got)
+	}
+}
@@ -0,0 +1,156 @@
+package search
+
+import (
+	"log"
+	"math"
+	"net/url"
+	"strings"
+)
+
+// Поля документа для BM25F
+const (
+	FieldTitle = iota
+	FieldHeadings
+	FieldBody
+	FieldURL
+	FieldAnchors
+	numFields
+)
+
+// FieldBoosts веса полей BM25F: совпадение в заголовке важнее совпадения в тексте
+type FieldBoosts struct {
+	Title    float64
+	Headings float64
+	Body     float64
+	URL      float64
+	Anchors  float64
+}
+
+// DefaultFieldBoosts веса полей по умолчанию
+var DefaultFieldBoosts = FieldBoosts{Title: 3, Headings: 2, Body: 1, URL: 1.5, Anchors: 1.5}
+
+// Параметры BM25 по умолчанию
+const (
+	DefaultBM25K1 = 1.2
+	DefaultBM25B  = 0.75
+)
+
+// BM25 ранжирование BM25F по полям документа: заголовок, заголовки разделов
+// и "хлебные крошки", текст с описанием, путь URL, тексты входящих ссылок. Частоты полей складываются
+// с весами и нормализуются по длине каждого поля, затем насыщаются через K1.
+type BM25 struct {
+	K1     float64
+	B      float64
+	Boosts FieldBoosts
+
+	// Analyzer токенизация и нормализация текста документов и запросов
+	Analyzer *Analyzer
+
+	Documents []Document
+	NumDocs   int
+
+	index *invertedIndex
+}
+
+// NewBM25 создает BM25F индекс с параметрами по умолчанию
+func NewBM25() *BM25 {
+	return &BM25{
+		K1:       DefaultBM25K1,
+		B:        DefaultBM25B,
+		Boosts:   DefaultFieldBoosts,
+		Analyzer: NewRussianAnalyzer(),
+		index:    newInvertedIndex(),
+	}
+}
+
+// documentFields разбивает документ на поля BM25F
+func documentFields(doc Document) [numFields]string {
+	var fields [numFields]string
+	fields[FieldTitle] = doc.Title
+	fields[FieldHeadings] = strings.Join(doc.Breadcrumbs, " ") + " " + strings.Join(doc.Section, " ")
+	fields[FieldBody] = doc.Description + " " + doc.Text
+	if u, err := url.Parse(doc.URL); err == nil {
+		fields[FieldURL] = u.Path
+	}
+	fields[FieldAnchors] = strings.Join(doc.Anchors, " ")
+	return fields
+}
+
+// BuildIndex строит индекс для поиска. K1, B и веса полей учитываются
+// при построении: после их изменения индекс нужно построить заново.
+func (bm *BM25) BuildIndex(documents []Document) {
+	bm.Documents = documents
+	bm.NumDocs = len(documents)
+	bm.index = newInvertedIndex()
+
+	fieldFreqs := make([][numFields]map[string]int, bm.NumDocs)
+	fieldLens := make([][numFields]int, bm.NumDocs)
+	var avgLens [numFields]float64
+
+	df := make(map[string]int)
+	for i, doc := range documents {
+		seen := make(map[string]bool)
+		for f, text := range documentFields(doc) {
+			tokens := bm.Analyzer.Analyze(text)
+			freq := make(map[string]int, len(tokens))
+			for _, token := range tokens {
+				freq[token]++
+				if !seen[token] {
+					seen[token] = true
+					df[token]++
+				}
+			}
+			fieldFreqs[i][f] = freq
+			fieldLens[i][f] = len(tokens)
+			avgLens[f] += float64(len(tokens))
+		}
+	}
+	for f := range avgLens {
+		if bm.NumDocs > 0 {
+			avgLens[f] /= float64(bm.NumDocs)
+		}
+	}
+
+	for i := range fieldFreqs {
+		// Взвешенная частота терма с нормализацией длины по каждому полю
+		tf := make(map[string]float64)
+		for f := 0; f < numFields; f++ {
+			if avgLens[f] == 0 {
+				continue
+			}
+			norm := 1 - bm.B + bm.B*float64(fieldLens[i][f])/avgLens[f]
+			for term, freq := range fieldFreqs[i][f] {
+				tf[term] += bm.boost(f) * float64(freq) / norm
+			}
+		}
+
+		// IDF в варианте BM25 с +1, чтобы частые термы не давали отрицательный вклад
+		for term, weight := range tf {
+			idf := math.Log(1 + (float64(bm.NumDocs)-float64(df[term])+0.5)/(float64(df[term])+0.5))
+			bm.index.add(term, i, idf*weight/(bm.K1+weight))
+		}
+	}
+	bm.index.compact()
+
+	log.Printf("Индекс BM25F построен: %d документов, %d уникальных термов", bm.NumDocs, bm.index.numTerms())
+}
+
+// boost вес поля
+func (bm *BM25) boost(field int) float64 {
+	switch field {
+	case FieldTitle:
+		return bm.Boosts.Title
+	case FieldHeadings:
+		return bm.Boosts.Headings
+	case FieldURL:
+		return bm.Boosts.URL
+	case FieldAnchors:
+		return bm.Boosts.Anchors
+	}
+	return bm.Boosts.Body
+}
+
+// Search ищет наиболее релевантные документы
+func (bm *BM25) Search(query string, topK int) []SearchResult {
+	return searchResults(bm.Documents, bm.index.search(bm.Analyzer.Analyze(query), topK))
+}
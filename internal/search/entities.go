@@ -0,0 +1,134 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Entity контактная сущность из data/entities.json (формат скрапера)
+type Entity struct {
+	Type       string   `json:"type"`
+	Value      string   `json:"value"`
+	Department string   `json:"department,omitempty"`
+	Context    string   `json:"context,omitempty"`
+	Title      string   `json:"title,omitempty"`
+	URLs       []string `json:"urls"`
+}
+
+// entityLabels подписи типов сущностей в контексте для модели
+var entityLabels = map[string]string{
+	"phone":      "Телефон",
+	"email":      "Email",
+	"address":    "Адрес",
+	"hours":      "Режим работы",
+	"department": "Подразделение",
+}
+
+// contactWords слова, по которым вопрос считается вопросом о контактах
+var contactWords = []string{
+	"связат", "контакт", "телефон", "позвонит", "звонит", "номер",
+	"почт", "email", "e-mail", "написать",
+	"адрес", "где находит", "как добрат", "как проехать", "расположен",
+	"режим работы", "часы работы", "график работы", "время работы", "когда работает",
+	"приемн", "приёмн",
+}
+
+// EntityStore справочник контактов для ответов на вопросы о контактах
+type EntityStore struct {
+	Entities []Entity
+}
+
+// LoadEntities загружает сущности из JSON файла
+func LoadEntities(filename string) (*EntityStore, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	store := &EntityStore{}
+	if err := json.Unmarshal(data, &store.Entities); err != nil {
+		return nil, fmt.Errorf("ошибка парсинга JSON: %w", err)
+	}
+
+	log.Printf("Загружено %d контактных сущностей из %s", len(store.Entities), filename)
+	return store, nil
+}
+
+// IsContactQuery определяет, спрашивает ли пользователь о контактах
+func IsContactQuery(query string) bool {
+	query = strings.ToLower(query)
+	for _, word := range contactWords {
+		if strings.Contains(query, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup возвращает сущности для вопроса: сначала те, у которых подразделение
+// или контекст совпадает со словами вопроса, затем общие контакты без подразделения,
+// упоминаемые на большем числе страниц. Контакты чужих подразделений не подставляются.
+func (es *EntityStore) Lookup(query string, limit int) []Entity {
+	queryWords := words(query)
+
+	type scored struct {
+		entity Entity
+		score  int
+	}
+	var results []scored
+	for _, e := range es.Entities {
+		if e.Type == "department" {
+			continue
+		}
+		score := matchScore(queryWords, e.Department+" "+e.Context)
+		if score == 0 && e.Department != "" {
+			continue
+		}
+		results = append(results, scored{entity: e, score: score})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		return len(results[i].entity.URLs) > len(results[j].entity.URLs)
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	entities := make([]Entity, len(results))
+	for i, r := range results {
+		entities[i] = r.entity
+	}
+	return entities
+}
+
+// GetContextForQuery формирует блок контактов для промпта; пустая строка,
+// если вопрос не о контактах или контакты не найдены
+func (es *EntityStore) GetContextForQuery(query string, limit int) string {
+	if !IsContactQuery(query) {
+		return ""
+	}
+	entities := es.Lookup(query, limit)
+	if len(entities) == 0 {
+		return ""
+	}
+
+	context := "Контактные данные из базы знаний:\n\n"
+	for _, e := range entities {
+		line := fmt.Sprintf("- %s: %s", entityLabels[e.Type], e.Value)
+		if e.Department != "" {
+			line += fmt.Sprintf(" (%s)", e.Department)
+		}
+		if len(e.URLs) > 0 {
+			line += fmt.Sprintf(" [URL: %s]", e.URLs[0])
+		}
+		context += line + "\n"
+	}
+	return context
+}
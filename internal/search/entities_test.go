@@ -0,0 +1,58 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func lookupValues(entities []Entity) []string {
+	values := make([]string, len(entities))
+	for i, e := range entities {
+		values[i] = e.Value
+	}
+	return values
+}
+
+func TestEntityLookup(t *testing.T) {
+	store := &EntityStore{Entities: []Entity{
+		{Type: "phone", Value: "+7 (495) 111-11-11", Department: "Приемная комиссия",
+			Context: "Приемная комиссия: +7 (495) 111-11-11", URLs: []string{"/a"}},
+		{Type: "phone", Value: "+7 (495) 222-22-22", Department: "Отдел кадров",
+			Context: "Отдел кадров: +7 (495) 222-22-22", URLs: []string{"/a", "/b", "/c"}},
+		{Type: "email", Value: "info@example.ru",
+			Context: "Пишите нам: info@example.ru", URLs: []string{"/a", "/b"}},
+		{Type: "department", Value: "Отдел кадров", URLs: []string{"/a", "/b", "/c", "/d"}},
+	}}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// Совпавшее подразделение первым, затем общие контакты
+		{"Телефон отдела кадров", []string{"+7 (495) 222-22-22", "info@example.ru"}},
+		{"Как связаться с приемной комиссией?", []string{"+7 (495) 111-11-11", "info@example.ru"}},
+		// Без совпадений контакты подразделений не подставляются
+		{"Как с вами связаться?", []string{"info@example.ru"}},
+	}
+	for _, tt := range tests {
+		if got := lookupValues(store.Lookup(tt.query, 5)); !slices.Equal(got, tt.want) {
+			t.Errorf("Lookup(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	if got := store.Lookup("Телефон отдела кадров", 1); len(got) != 1 || got[0].Value != "+7 (495) 222-22-22" {
+		t.Errorf("Lookup с limit 1 = %v", lookupValues(got))
+	}
+}
+
+func TestEntityLookupOnlyDepartments(t *testing.T) {
+	store := &EntityStore{Entities: []Entity{
+		{Type: "phone", Value: "+7 (495) 222-22-22", Department: "Отдел кадров", URLs: []string{"/a"}},
+	}}
+	if got := store.Lookup("Как с вами связаться?", 5); len(got) != 0 {
+		t.Errorf("Lookup = %v, want пусто", lookupValues(got))
+	}
+	if ctx := store.GetContextForQuery("Как с вами связаться?", 5); ctx != "" {
+		t.Errorf("GetContextForQuery = %q, want пусто", ctx)
+	}
+}
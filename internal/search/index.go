@@ -0,0 +1,192 @@
+package search
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// posting вхождение терма в документ с готовым вкладом в score
+type posting struct {
+	doc    int32
+	weight float32
+}
+
+// invertedIndex обратный индекс: для каждого терма — документы по возрастанию
+// номера с весом терма в документе. Термы хранятся по ID, строка терма
+// хранится один раз, частоты по документам не хранятся вовсе.
+type invertedIndex struct {
+	termIDs   map[string]int32
+	postings  [][]posting
+	maxWeight []float32
+}
+
+func newInvertedIndex() *invertedIndex {
+	return &invertedIndex{termIDs: make(map[string]int32)}
+}
+
+// termID возвращает ID терма, добавляя новый
+func (ix *invertedIndex) termID(term string) int32 {
+	id, ok := ix.termIDs[term]
+	if !ok {
+		id = int32(len(ix.postings))
+		ix.termIDs[term] = id
+		ix.postings = append(ix.postings, nil)
+		ix.maxWeight = append(ix.maxWeight, 0)
+	}
+	return id
+}
+
+// add добавляет вхождение терма; документы должны идти по возрастанию номера
+func (ix *invertedIndex) add(term string, doc int, weight float64) {
+	if weight <= 0 {
+		return
+	}
+	id := ix.termID(term)
+	ix.postings[id] = append(ix.postings[id], posting{doc: int32(doc), weight: float32(weight)})
+	if float32(weight) > ix.maxWeight[id] {
+		ix.maxWeight[id] = float32(weight)
+	}
+}
+
+// compact освобождает запас емкости списков после построения
+func (ix *invertedIndex) compact() {
+	for id, list := range ix.postings {
+		ix.postings[id] = append([]posting(nil), list...)
+	}
+}
+
+// numTerms число термов в индексе
+func (ix *invertedIndex) numTerms() int {
+	return len(ix.postings)
+}
+
+// scoredDoc номер документа и его score
+type scoredDoc struct {
+	doc   int32
+	score float64
+}
+
+// search возвращает до k документов с наибольшей суммой весов термов запроса.
+// Термы обрабатываются по убыванию максимального вклада. Когда оставшиеся термы
+// уже не могут поднять новый документ выше k-го результата, новые документы
+// перестают добавляться и обновляются только найденные.
+func (ix *invertedIndex) search(terms []string, k int) []scoredDoc {
+	if k <= 0 {
+		return nil
+	}
+
+	// Повтор терма в запросе увеличивает его вес
+	qtf := make(map[int32]int)
+	for _, term := range terms {
+		if id, ok := ix.termIDs[term]; ok {
+			qtf[id]++
+		}
+	}
+	ids := make([]int32, 0, len(qtf))
+	remaining := 0.0
+	bound := func(id int32) float64 {
+		return float64(qtf[id]) * float64(ix.maxWeight[id])
+	}
+	for id := range qtf {
+		ids = append(ids, id)
+		remaining += bound(id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if bound(ids[i]) != bound(ids[j]) {
+			return bound(ids[i]) > bound(ids[j])
+		}
+		return ids[i] < ids[j]
+	})
+
+	acc := make(map[int32]float64)
+	top := newTopK(k)
+	addNew := true
+	for _, id := range ids {
+		// Запас на ошибку округления float32
+		if addNew && top.full() && remaining*(1+1e-6) < top.threshold() {
+			addNew = false
+		}
+		w := float64(qtf[id])
+		for _, p := range ix.postings[id] {
+			score, ok := acc[p.doc]
+			if !ok && !addNew {
+				continue
+			}
+			score += w * float64(p.weight)
+			acc[p.doc] = score
+			top.update(scoredDoc{doc: p.doc, score: score})
+		}
+		remaining -= bound(id)
+	}
+
+	result := top.docs
+	sort.Slice(result, func(i, j int) bool { return better(result[i], result[j]) })
+	return result
+}
+
+// better порядок выдачи: по убыванию score, при равенстве — по номеру документа
+func better(a, b scoredDoc) bool {
+	if a.score != b.score {
+		return a.score > b.score
+	}
+	return a.doc < b.doc
+}
+
+// topK k лучших документов по текущему score: куча с худшим из отобранных
+// наверху. Score документов только растут, поэтому куча обновляется на каждом
+// вхождении, и k-й результат известен без пересчета по всем документам.
+type topK struct {
+	docs []scoredDoc
+	pos  map[int32]int
+	k    int
+}
+
+func newTopK(k int) *topK {
+	return &topK{docs: make([]scoredDoc, 0, k), pos: make(map[int32]int, k), k: k}
+}
+
+func (t *topK) Len() int           { return len(t.docs) }
+func (t *topK) Less(i, j int) bool { return better(t.docs[j], t.docs[i]) }
+func (t *topK) Swap(i, j int) {
+	t.docs[i], t.docs[j] = t.docs[j], t.docs[i]
+	t.pos[t.docs[i].doc] = i
+	t.pos[t.docs[j].doc] = j
+}
+func (t *topK) Push(x any) {
+	d := x.(scoredDoc)
+	t.pos[d.doc] = len(t.docs)
+	t.docs = append(t.docs, d)
+}
+func (t *topK) Pop() any {
+	x := t.docs[len(t.docs)-1]
+	t.docs = t.docs[:len(t.docs)-1]
+	delete(t.pos, x.doc)
+	return x
+}
+
+// full отобрано ли уже k документов
+func (t *topK) full() bool {
+	return len(t.docs) >= t.k
+}
+
+// threshold score k-го по величине документа
+func (t *topK) threshold() float64 {
+	return t.docs[0].score
+}
+
+// update учитывает новый score документа; score не может уменьшаться
+func (t *topK) update(d scoredDoc) {
+	if i, ok := t.pos[d.doc]; ok {
+		t.docs[i].score = d.score
+		heap.Fix(t, i)
+		return
+	}
+	if !t.full() {
+		heap.Push(t, d)
+	} else if better(d, t.docs[0]) {
+		delete(t.pos, t.docs[0].doc)
+		t.docs[0] = d
+		t.pos[d.doc] = 0
+		heap.Fix(t, 0)
+	}
+}
@@ -0,0 +1,140 @@
+package search
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"testing"
+)
+
+// linearSearch эталон для invertedIndex.search: складывает веса всех
+// вхождений без отсечения и сортирует все документы. Термы обходятся в том же
+// порядке, что и в search, поэтому суммы совпадают до бита.
+func linearSearch(ix *invertedIndex, terms []string, k int) []scoredDoc {
+	qtf := make(map[int32]int)
+	for _, term := range terms {
+		if id, ok := ix.termIDs[term]; ok {
+			qtf[id]++
+		}
+	}
+	ids := make([]int32, 0, len(qtf))
+	for id := range qtf {
+		ids = append(ids, id)
+	}
+	bound := func(id int32) float64 {
+		return float64(qtf[id]) * float64(ix.maxWeight[id])
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if bound(ids[i]) != bound(ids[j]) {
+			return bound(ids[i]) > bound(ids[j])
+		}
+		return ids[i] < ids[j]
+	})
+
+	acc := make(map[int32]float64)
+	for _, id := range ids {
+		for _, p := range ix.postings[id] {
+			acc[p.doc] += float64(qtf[id]) * float64(p.weight)
+		}
+	}
+	var all []scoredDoc
+	for doc, score := range acc {
+		if score > 0 {
+			all = append(all, scoredDoc{doc: doc, score: score})
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return better(all[i], all[j]) })
+	if len(all) > k {
+		all = all[:k]
+	}
+	return all
+}
+
+func TestIndexSearchMatchesLinearScan(t *testing.T) {
+	quietLog(t)
+	docs := fixtureCorpus(600)
+
+	queries := append(slices.Clone(benchQueries),
+		"обучение обучение обучение", // повтор терма увеличивает вес
+		"тоннель эскалатор локомотив автоматика рельс",
+		"слушатель",
+		"несуществующее слово",
+	)
+	rankers := map[string]interface {
+		Ranker
+		searchIndex() *invertedIndex
+		analyze(string) []string
+	}{
+		RankerTFIDF: tfidfIndex{NewTFIDF()},
+		RankerBM25:  bm25Index{NewBM25()},
+	}
+
+	for name, ranker := range rankers {
+		ranker.BuildIndex(docs)
+		ix := ranker.searchIndex()
+		for _, query := range queries {
+			terms := ranker.analyze(query)
+			for _, k := range []int{1, 3, 10, 50, len(docs) + 10} {
+				t.Run(fmt.Sprintf("%s/%s/%d", name, query, k), func(t *testing.T) {
+					got := ix.search(terms, k)
+					want := linearSearch(ix, terms, k)
+					if !slices.Equal(got, want) {
+						t.Fatalf("индекс: %v\nполный перебор: %v", got, want)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestIndexSearchTiesAndShortResults(t *testing.T) {
+	quietLog(t)
+	// Одинаковые документы дают равный score: порядок — по номеру документа
+	docs := []Document{
+		{Text: "курсы машинистов метрополитена"},
+		{Text: "расписание занятий"},
+		{Text: "курсы машинистов метрополитена"},
+		{Text: "курсы машинистов метрополитена"},
+		{Text: "стоимость курсов и расписание"},
+		{Text: "общежитие для слушателей"},
+	}
+	tf := NewTFIDF()
+	tf.BuildIndex(docs)
+
+	results := tf.Search("курсы машинистов", 10)
+	ids := make([]int32, len(results))
+	for i, d := range tf.index.search(tf.Analyzer.Analyze("курсы машинистов"), 10) {
+		ids[i] = d.doc
+	}
+	// K больше числа совпадений: возвращаются только совпавшие документы
+	if want := []int32{0, 2, 3, 4}; !slices.Equal(ids, want) {
+		t.Fatalf("документы %v, want %v", ids, want)
+	}
+	if results[0].Score != results[1].Score || results[1].Score != results[2].Score {
+		t.Errorf("score одинаковых документов различается: %v", results)
+	}
+	if results[3].Score >= results[2].Score {
+		t.Errorf("документ без второго терма не ниже: %v", results)
+	}
+
+	for _, k := range []int{1, 2, 3} {
+		got := tf.index.search(tf.Analyzer.Analyze("курсы машинистов"), k)
+		if len(got) != k || got[k-1].doc != ids[k-1] {
+			t.Errorf("k=%d: %v", k, got)
+		}
+	}
+	if got := tf.Search("курсы", 0); len(got) != 0 {
+		t.Errorf("k=0: %v", got)
+	}
+}
+
+// tfidfIndex и bm25Index открывают тесту индекс и анализатор ранжирования
+type tfidfIndex struct{ *TFIDF }
+
+func (r tfidfIndex) searchIndex() *invertedIndex  { return r.index }
+func (r tfidfIndex) analyze(text string) []string { return r.Analyzer.Analyze(text) }
+
+type bm25Index struct{ *BM25 }
+
+func (r bm25Index) searchIndex() *invertedIndex  { return r.index }
+func (r bm25Index) analyze(text string) []string { return r.Analyzer.Analyze(text) }
@@ -0,0 +1,233 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"DriveHack/internal/programs"
+)
+
+// Program учебная программа из data/programs.json (формат скрапера)
+type Program struct {
+	Name        string   `json:"name"`
+	URL         string   `json:"url"`
+	Format      string   `json:"format,omitempty"`
+	Duration    string   `json:"duration,omitempty"`
+	Hours       int      `json:"hours,omitempty"`
+	Audience    string   `json:"audience,omitempty"`
+	Cost        string   `json:"cost,omitempty"`
+	Price       int      `json:"price,omitempty"`
+	Free        bool     `json:"free,omitempty"`
+	StartDates  []string `json:"start_dates,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Source      string   `json:"source,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// ProgramFilter условия отбора программ; нулевые значения не ограничивают выборку
+type ProgramFilter struct {
+	// Query слова, которые должны встречаться в названии, аудитории или описании
+	Query    string
+	Format   string
+	Audience string
+	MinHours int
+	MaxHours int
+	MaxPrice int
+	FreeOnly bool
+	Limit    int
+}
+
+// programWords слова, по которым вопрос считается вопросом о программах
+var programWords = []string{
+	"программ", "курс", "обучени", "обучат", "учиться", "тренинг", "семинар",
+	"повышени", "переподготовк", "квалификаци",
+}
+
+// ProgramCatalog каталог программ обучения с поиском и фильтрами
+type ProgramCatalog struct {
+	Programs []Program
+}
+
+// LoadPrograms загружает каталог программ из JSON файла
+func LoadPrograms(filename string) (*ProgramCatalog, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+
+	catalog := &ProgramCatalog{}
+	if err := json.Unmarshal(data, &catalog.Programs); err != nil {
+		return nil, fmt.Errorf("ошибка парсинга JSON: %w", err)
+	}
+
+	log.Printf("Загружено %d программ обучения из %s", len(catalog.Programs), filename)
+	return catalog, nil
+}
+
+// words разбивает текст на слова в нижнем регистре (включая кириллицу)
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// stemPrefix начало слова для сравнения без учета окончаний
+func stemPrefix(word string) string {
+	runes := []rune(word)
+	if len(runes) > 5 {
+		runes = runes[:5]
+	}
+	return string(runes)
+}
+
+// matchScore число слов запроса (длиннее трех букв), найденных в тексте
+func matchScore(queryWords []string, text string) int {
+	text = strings.ToLower(text)
+	score := 0
+	for _, word := range queryWords {
+		if len([]rune(word)) > 3 && strings.Contains(text, stemPrefix(word)) {
+			score++
+		}
+	}
+	return score
+}
+
+// Filter возвращает программы, подходящие под условия. Если задан Query,
+// результаты упорядочены по числу совпавших слов, иначе по названию.
+// Формат из фильтра нормализуется так же, как формат программ в каталоге.
+func (pc *ProgramCatalog) Filter(f ProgramFilter) []Program {
+	queryWords := words(f.Query)
+	format := programs.NormalizeFormat(f.Format)
+
+	type scored struct {
+		program Program
+		score   int
+	}
+	var results []scored
+	for _, p := range pc.Programs {
+		if format != "" && !strings.EqualFold(programs.NormalizeFormat(p.Format), format) {
+			continue
+		}
+		if f.FreeOnly && !p.Free {
+			continue
+		}
+		if f.MaxPrice > 0 && !p.Free && (p.Price == 0 || p.Price > f.MaxPrice) {
+			continue
+		}
+		if f.MinHours > 0 && p.Hours < f.MinHours {
+			continue
+		}
+		if f.MaxHours > 0 && (p.Hours == 0 || p.Hours > f.MaxHours) {
+			continue
+		}
+		if f.Audience != "" && matchScore(words(f.Audience), p.Audience) == 0 {
+			continue
+		}
+
+		score := 0
+		if len(queryWords) > 0 {
+			score = matchScore(queryWords, p.Name+" "+p.Audience+" "+p.Description+" "+strings.Join(p.Tags, " "))
+			if score == 0 {
+				continue
+			}
+		}
+		results = append(results, scored{program: p, score: score})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		return results[i].program.Name < results[j].program.Name
+	})
+
+	if f.Limit > 0 && len(results) > f.Limit {
+		results = results[:f.Limit]
+	}
+	programs := make([]Program, len(results))
+	for i, r := range results {
+		programs[i] = r.program
+	}
+	return programs
+}
+
+// IsProgramQuery определяет, спрашивает ли пользователь о программах обучения
+func IsProgramQuery(query string) bool {
+	query = strings.ToLower(query)
+	for _, word := range programWords {
+		if strings.Contains(query, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterFromQuery извлекает из вопроса формат обучения и условие бесплатности
+func filterFromQuery(query string) ProgramFilter {
+	q := strings.ToLower(query)
+	var f ProgramFilter
+	switch {
+	case strings.Contains(q, "смешан") || strings.Contains(q, "очно-заочн"):
+		f.Format = programs.FormatBlended
+	case strings.Contains(q, "онлайн") || strings.Contains(q, "дистанц") || strings.Contains(q, "удаленн") || strings.Contains(q, "удалённ"):
+		f.Format = programs.FormatOnline
+	case strings.Contains(q, "очн") && !strings.Contains(q, "заочн"):
+		f.Format = programs.FormatOffline
+	}
+	if strings.Contains(q, "бесплатн") {
+		f.FreeOnly = true
+	}
+	return f
+}
+
+// GetContextForQuery формирует список программ для промпта: сначала
+// подходящие по словам вопроса, затем остальные из каталога; пустая строка,
+// если вопрос не о программах
+func (pc *ProgramCatalog) GetContextForQuery(query string, limit int) string {
+	if !IsProgramQuery(query) {
+		return ""
+	}
+
+	// Слова вопроса только упорядочивают список: на вопрос "какие программы
+	// есть" нужен весь каталог, а не программы со словом "какие"
+	programs := pc.Filter(filterFromQuery(query))
+	if len(programs) == 0 {
+		return ""
+	}
+	queryWords := words(query)
+	sort.SliceStable(programs, func(i, j int) bool {
+		return matchScore(queryWords, programs[i].Name+" "+programs[i].Audience) >
+			matchScore(queryWords, programs[j].Name+" "+programs[j].Audience)
+	})
+	total := len(programs)
+	if len(programs) > limit {
+		programs = programs[:limit]
+	}
+
+	context := fmt.Sprintf("Каталог программ обучения (%d из %d):\n\n", len(programs), total)
+	for i, p := range programs {
+		context += fmt.Sprintf("%d. %s\n", i+1, p.Name)
+		if p.Format != "" {
+			context += fmt.Sprintf("   Формат: %s\n", p.Format)
+		}
+		if p.Duration != "" {
+			context += fmt.Sprintf("   Продолжительность: %s\n", p.Duration)
+		}
+		if p.Audience != "" {
+			context += fmt.Sprintf("   Для кого: %s\n", p.Audience)
+		}
+		if p.Cost != "" {
+			context += fmt.Sprintf("   Стоимость: %s\n", p.Cost)
+		}
+		if len(p.StartDates) > 0 {
+			context += fmt.Sprintf("   Начало: %s\n", strings.Join(p.StartDates, ", "))
+		}
+		context += fmt.Sprintf("   (URL: %s)\n", p.URL)
+	}
+	return context
+}
@@ -0,0 +1,41 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func programNames(programs []Program) []string {
+	names := make([]string, len(programs))
+	for i, p := range programs {
+		names[i] = p.Name
+	}
+	return names
+}
+
+func TestProgramFilterFormat(t *testing.T) {
+	catalog := &ProgramCatalog{Programs: []Program{
+		{Name: "Бухгалтерский учет", Format: "очно"},
+		{Name: "Веб-разработка", Format: "онлайн"},
+		{Name: "Менеджмент", Format: "смешанный"},
+		{Name: "Охрана труда", Format: "по выбору слушателя"},
+	}}
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"очно", []string{"Бухгалтерский учет"}},
+		{"Очная форма", []string{"Бухгалтерский учет"}},
+		{"дистанционно", []string{"Веб-разработка"}},
+		{"Очно-заочная", []string{"Менеджмент"}},
+		{"По выбору слушателя", []string{"Охрана труда"}},
+		{"", []string{"Бухгалтерский учет", "Веб-разработка", "Менеджмент", "Охрана труда"}},
+	}
+	for _, tt := range tests {
+		got := programNames(catalog.Filter(ProgramFilter{Format: tt.format}))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Format %q: %v, want %v", tt.format, got, tt.want)
+		}
+	}
+}
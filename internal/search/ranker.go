@@ -0,0 +1,26 @@
+package search
+
+import "fmt"
+
+// Названия алгоритмов ранжирования
+const (
+	RankerTFIDF = "tfidf"
+	RankerBM25  = "bm25"
+)
+
+// Ranker алгоритм ранжирования документов базы знаний
+type Ranker interface {
+	BuildIndex(documents []Document)
+	Search(query string, topK int) []SearchResult
+}
+
+// NewRanker создает ранжирование по названию: tfidf или bm25 (BM25F по полям)
+func NewRanker(name string) (Ranker, error) {
+	switch name {
+	case "", RankerTFIDF:
+		return NewTFIDF(), nil
+	case RankerBM25:
+		return NewBM25(), nil
+	}
+	return nil, fmt.Errorf("неизвестный алгоритм ранжирования: %s", name)
+}
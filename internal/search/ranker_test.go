@@ -0,0 +1,115 @@
+package search
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// corpusWords словарь тестового корпуса: частые слова в начале,
+// формы одного слова сводятся стеммером к одной основе
+var corpusWords = strings.Fields(`
+	обучение программа курс центр слушатель занятие преподаватель группа
+	обучения программы курсы центра слушателей занятия преподаватели группы
+	квалификация переподготовка удостоверение диплом расписание стоимость
+	метрополитен машинист диспетчер электромеханик охрана труда безопасность
+	дистанционно очно вечерний субботний практика экзамен аттестация стажировка
+	документы заявление договор оплата скидка льгота общежитие столовая
+	библиотека тренажер симулятор подвижной состав тоннель станция эскалатор
+	поезд локомотив сигнализация связь автоматика путь контактный рельс
+`)
+
+// fixtureCorpus детерминированный корпус из n документов с распределением
+// слов по Ципфу. Каждый десятый документ повторяет предыдущий, чтобы
+// в выдаче были документы с одинаковым score.
+func fixtureCorpus(n int) []Document {
+	rng := rand.New(rand.NewSource(1))
+	zipf := rand.NewZipf(rng, 1.1, 2, uint64(len(corpusWords)-1))
+
+	docs := make([]Document, n)
+	for i := range docs {
+		if i%10 == 9 {
+			docs[i] = docs[i-1]
+			docs[i].ID = i
+			continue
+		}
+		text := make([]string, 40+rng.Intn(120))
+		for j := range text {
+			text[j] = corpusWords[zipf.Uint64()]
+		}
+		docs[i] = Document{
+			ID:          i,
+			URL:         fmt.Sprintf("https://example.ru/page%d", i/3),
+			Title:       strings.Join(text[:3], " "),
+			Text:        strings.Join(text, " "),
+			Breadcrumbs: []string{"Главная", corpusWords[rng.Intn(len(corpusWords))]},
+			Anchors:     []string{corpusWords[rng.Intn(len(corpusWords))]},
+		}
+	}
+	return docs
+}
+
+// benchQueries запросы бенчмарков: частые, редкие и смешанные слова
+var benchQueries = []string{
+	"программы обучения",
+	"курсы повышения квалификации машинистов",
+	"стоимость обучения и скидки",
+	"тренажер подвижной состав эскалатор",
+	"расписание вечерних занятий группы",
+	"общежитие",
+}
+
+// quietLog отключает лог построения индекса на время теста
+func quietLog(tb testing.TB) {
+	tb.Helper()
+	out := log.Writer()
+	log.SetOutput(io.Discard)
+	tb.Cleanup(func() { log.SetOutput(out) })
+}
+
+func benchmarkRanker(b *testing.B, newRanker func() Ranker) {
+	quietLog(b)
+	docs := fixtureCorpus(2000)
+
+	b.Run("build", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			newRanker().BuildIndex(docs)
+		}
+	})
+
+	ranker := newRanker()
+	ranker.BuildIndex(docs)
+	b.Run("search", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ranker.Search(benchQueries[i%len(benchQueries)], 10)
+		}
+	})
+}
+
+func BenchmarkTFIDF(b *testing.B) {
+	benchmarkRanker(b, func() Ranker { return NewTFIDF() })
+}
+
+func BenchmarkBM25(b *testing.B) {
+	benchmarkRanker(b, func() Ranker { return NewBM25() })
+}
+
+func TestNewRanker(t *testing.T) {
+	for name, want := range map[string]string{"": "*search.TFIDF", RankerTFIDF: "*search.TFIDF", RankerBM25: "*search.BM25"} {
+		ranker, err := NewRanker(name)
+		if err != nil {
+			t.Fatalf("NewRanker(%q): %v", name, err)
+		}
+		if got := fmt.Sprintf("%T", ranker); got != want {
+			t.Errorf("NewRanker(%q) = %s, want %s", name, got, want)
+		}
+	}
+	if _, err := NewRanker("lucene"); err == nil {
+		t.Error("NewRanker(\"lucene\"): ошибки нет")
+	}
+}
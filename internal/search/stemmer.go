@@ -0,0 +1,163 @@
+package search
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Русский стеммер Snowball (snowballstem.org/algorithms/russian/stemmer.html).
+// Окончания ищутся только в области RV — после первой гласной; словообразующие
+// суффиксы "ост", "ость" — в области R2.
+
+// russianVowels гласные алфавита Snowball
+const russianVowels = "аеиоуыэюя"
+
+// suffixGroup окончания одной группы; afterAYa — окончание считается только
+// после "а" или "я", которые остаются в слове
+type suffixGroup struct {
+	endings  []string
+	afterAYa bool
+}
+
+var (
+	perfectiveGerund = []suffixGroup{
+		{endings: []string{"в", "вши", "вшись"}, afterAYa: true},
+		{endings: []string{"ив", "ивши", "ившись", "ыв", "ывши", "ывшись"}},
+	}
+	adjective = []suffixGroup{
+		{endings: []string{"ее", "ие", "ые", "ое", "ими", "ыми", "ей", "ий", "ый", "ой", "ем", "им", "ым", "ом",
+			"его", "ого", "ему", "ому", "их", "ых", "ую", "юю", "ая", "яя", "ою", "ею"}},
+	}
+	participle = []suffixGroup{
+		{endings: []string{"ем", "нн", "вш", "ющ", "щ"}, afterAYa: true},
+		{endings: []string{"ивш", "ывш", "ующ"}},
+	}
+	reflexive = []suffixGroup{
+		{endings: []string{"ся", "сь"}},
+	}
+	verb = []suffixGroup{
+		{endings: []string{"ла", "на", "ете", "йте", "ли", "й", "л", "ем", "н", "ло", "но", "ет", "ют", "ны", "ть", "ешь", "нно"}, afterAYa: true},
+		{endings: []string{"ила", "ыла", "ена", "ейте", "уйте", "ите", "или", "ыли", "ей", "уй", "ил", "ыл", "им", "ым", "ен",
+			"ило", "ыло", "ено", "ят", "ует", "уют", "ит", "ыт", "ены", "ить", "ыть", "ишь", "ую", "ю"}},
+	}
+	noun = []suffixGroup{
+		{endings: []string{"а", "ев", "ов", "ие", "ье", "е", "иями", "ями", "ами", "еи", "ии", "и", "ией", "ей", "ой", "ий", "й",
+			"иям", "ям", "ием", "ем", "ам", "ом", "о", "у", "ах", "иях", "ях", "ы", "ь", "ию", "ью", "ю", "ия", "ья", "я"}},
+	}
+	superlative = []suffixGroup{
+		{endings: []string{"ейш", "ейше"}},
+	}
+	derivational = []suffixGroup{
+		{endings: []string{"ост", "ость"}},
+	}
+)
+
+func isRussianVowel(r rune) bool {
+	return strings.ContainsRune(russianVowels, r)
+}
+
+// russianRegions возвращает начало областей RV и R2 (индексы в рунах)
+func russianRegions(word []rune) (rv, r2 int) {
+	rv, r2 = len(word), len(word)
+	i := 0
+	next := func(vowel bool) bool {
+		for i < len(word) && isRussianVowel(word[i]) != vowel {
+			i++
+		}
+		if i == len(word) {
+			return false
+		}
+		i++
+		return true
+	}
+
+	if !next(true) {
+		return
+	}
+	rv = i
+	// R1 — после первой согласной, следующей за гласной; R2 — то же внутри R1
+	if next(false) && next(true) && next(false) {
+		r2 = i
+	}
+	return
+}
+
+// findSuffix ищет самое длинное окончание из групп, целиком лежащее после
+// позиции limit. Возвращает длину окончания в рунах или 0. Если самое длинное
+// окончание требует "а"/"я" перед собой, а их нет, более короткие не проверяются.
+func findSuffix(word []rune, limit int, groups []suffixGroup) int {
+	s := string(word)
+	best, bestAYa := 0, false
+	for _, group := range groups {
+		for _, ending := range group.endings {
+			n := utf8.RuneCountInString(ending)
+			if n <= best || len(word)-n < limit || !strings.HasSuffix(s, ending) {
+				continue
+			}
+			best, bestAYa = n, group.afterAYa
+		}
+	}
+	if best == 0 {
+		return 0
+	}
+	if bestAYa {
+		p := len(word) - best - 1
+		if p < limit || (word[p] != 'а' && word[p] != 'я') {
+			return 0
+		}
+	}
+	return best
+}
+
+// removeSuffix отрезает самое длинное окончание из групп, если оно есть
+func removeSuffix(word []rune, limit int, groups []suffixGroup) ([]rune, bool) {
+	if n := findSuffix(word, limit, groups); n > 0 {
+		return word[:len(word)-n], true
+	}
+	return word, false
+}
+
+// StemRussian возвращает основу русского слова в нижнем регистре.
+// Слова не на кириллице только приводятся к нижнему регистру.
+func StemRussian(word string) string {
+	runes := []rune(strings.ReplaceAll(strings.ToLower(word), "ё", "е"))
+	rv, r2 := russianRegions(runes)
+	if rv == len(runes) {
+		return string(runes)
+	}
+
+	// Шаг 1: деепричастие, иначе возвратная частица и прилагательное, глагол или существительное
+	var ok bool
+	if runes, ok = removeSuffix(runes, rv, perfectiveGerund); !ok {
+		runes, _ = removeSuffix(runes, rv, reflexive)
+		if runes, ok = removeSuffix(runes, rv, adjective); ok {
+			runes, _ = removeSuffix(runes, rv, participle)
+		} else if runes, ok = removeSuffix(runes, rv, verb); !ok {
+			runes, _ = removeSuffix(runes, rv, noun)
+		}
+	}
+
+	// Шаг 2: конечная "и"
+	if len(runes) > rv && runes[len(runes)-1] == 'и' {
+		runes = runes[:len(runes)-1]
+	}
+
+	// Шаг 3: словообразующий суффикс в R2
+	runes, _ = removeSuffix(runes, max(r2, rv), derivational)
+
+	// Шаг 4: превосходная степень, двойная "н", мягкий знак
+	if runes, ok = removeSuffix(runes, rv, superlative); ok || endsWithNN(runes, rv) {
+		if endsWithNN(runes, rv) {
+			runes = runes[:len(runes)-1]
+		}
+	} else if len(runes) > rv && runes[len(runes)-1] == 'ь' {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes)
+}
+
+// endsWithNN проверяет, что слово оканчивается на "нн" в области RV
+func endsWithNN(word []rune, rv int) bool {
+	n := len(word)
+	return n-2 >= rv && word[n-1] == 'н' && word[n-2] == 'н'
+}
@@ -0,0 +1,58 @@
+package search
+
+import "testing"
+
+func TestStemRussian(t *testing.T) {
+	// Пары из эталонного словаря Snowball (voc.txt / output.txt)
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"абиссинию", "абиссин"},
+		{"автомобиль", "автомобил"},
+		{"важнейшие", "важн"},
+		{"красивая", "красив"},
+		{"бегающий", "бега"},
+		{"путешествовали", "путешествова"},
+		{"взволнованный", "взволнова"},
+		{"играющаяся", "игра"},
+		{"лошадьми", "лошадьм"},
+		{"подлинность", "подлин"},
+		{"бесконечности", "бесконечн"},
+		{"читаешь", "чита"},
+		{"благородство", "благородств"},
+		{"ёлка", "елк"},
+		// Регистр не влияет на основу
+		{"Программы", "программ"},
+		{"ЁЛКА", "елк"},
+		// Не кириллица и слова без гласных не меняются, кроме регистра
+		{"encoding", "encoding"},
+		{"UTF", "utf"},
+		{"2024", "2024"},
+		{"вз", "вз"},
+	}
+	for _, tt := range tests {
+		if got := StemRussian(tt.word); got != tt.want {
+			t.Errorf("StemRussian(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestStemRussianForms(t *testing.T) {
+	// Формы одного слова сводятся к одной основе
+	forms := map[string][]string{
+		"программ":   {"программа", "программы", "программам", "программой", "программами"},
+		"обучен":     {"обучение", "обучения", "обучением", "обучении"},
+		"курс":       {"курс", "курсы", "курсов", "курсами"},
+		"станц":      {"станция", "станции", "станцию"},
+		"квалификац": {"квалификация", "квалификации"},
+		"расписан":   {"расписание", "расписанию", "расписания"},
+	}
+	for stem, words := range forms {
+		for _, word := range words {
+			if got := StemRussian(word); got != stem {
+				t.Errorf("StemRussian(%q) = %q, want %q", word, got, stem)
+			}
+		}
+	}
+}
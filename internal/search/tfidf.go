@@ -6,154 +6,141 @@ import (
 	"log"
 	"math"
 	"os"
-	"regexp"
-	"sort"
 	"strings"
+
+	"DriveHack/internal/tokens"
 )
 
 // Document представляет документ для поиска
 type Document struct {
-	ID    int    `json:"id"`
-	URL   string `json:"url"`
-	Title string `json:"title"`
-	Text  string `json:"text"`
+	ID      int    `json:"id"`
+	ChunkID string `json:"chunk_id,omitempty"`
+	URL     string `json:"url"`
+	Title   string `json:"title"`
+	Text    string `json:"text"`
+
+	// Метаданные страницы из чанков скрапера
+	Language     string            `json:"language,omitempty"`
+	Charset      string            `json:"charset,omitempty"`
+	Description  string            `json:"description,omitempty"`
+	OpenGraph    map[string]string `json:"og,omitempty"`
+	Breadcrumbs  []string          `json:"breadcrumbs,omitempty"`
+	LastModified string            `json:"last_modified,omitempty"`
+	FetchedAt    string            `json:"fetched_at,omitempty"`
+	Depth        int               `json:"depth,omitempty"`
+
+	// Anchors тексты ссылок на страницу с других страниц сайта
+	Anchors []string `json:"anchors,omitempty"`
+
+	// Иерархия разделов: цепочка заголовков, тип "section" у родительского
+	// раздела и ID родителя у дочернего чанка
+	Section  []string `json:"section,omitempty"`
+	Kind     string   `json:"kind,omitempty"`
+	ParentID *int     `json:"parent_id,omitempty"`
 }
 
+// KindSection тип документа с полным текстом раздела страницы
+const KindSection = "section"
+
+// DefaultContextTokens бюджет контекста из базы знаний в токенах по умолчанию
+const DefaultContextTokens = 1500
+
+const (
+	// contextCandidates сколько найденных чанков рассматривается при сборке контекста
+	contextCandidates = 20
+	// minSourceTokens меньший остаток бюджета на обрезанный источник не тратится
+	minSourceTokens = 50
+)
+
 // SearchResult результат поиска
 type SearchResult struct {
 	Document Document
 	Score    float64
 }
 
-// TFIDF простая реализация TF-IDF алгоритма
+// TFIDF простая реализация TF-IDF алгоритма поверх обратного индекса
 type TFIDF struct {
 	Documents    []Document
 	DocLengths   []int
-	DocFreqs     []map[string]int
-	IDF          map[string]float64
 	NumDocs      int
+
+	// Analyzer токенизация и нормализация текста документов и запросов
+	Analyzer *Analyzer
+
+	index *invertedIndex
 }
 
 // NewTFIDF создает новый TF-IDF индекс
 func NewTFIDF() *TFIDF {
 	return &TFIDF{
-		DocFreqs: []map[string]int{},
-		IDF:      make(map[string]float64),
+		Analyzer: NewRussianAnalyzer(),
+		index:    newInvertedIndex(),
 	}
 }
 
-// tokenize разбивает текст на токены
-func tokenize(text string) []string {
-	// Приводим к нижнему регистру
-	text = strings.ToLower(text)
-	
-	// Оставляем только буквы и цифры
-	re := regexp.MustCompile(`\w+`)
-	tokens := re.FindAllString(text, -1)
-	
-	return tokens
-}
-
 // BuildIndex строит индекс для поиска
 func (tf *TFIDF) BuildIndex(documents []Document) {
 	tf.Documents = documents
 	tf.NumDocs = len(documents)
+	tf.index = newInvertedIndex()
 	
-	// Токенизируем все документы
-	tokenizedDocs := make([][]string, tf.NumDocs)
+	// Подсчитываем частоту термов в каждом документе
+	docFreqs := make([]map[string]int, tf.NumDocs)
 	tf.DocLengths = make([]int, tf.NumDocs)
+	df := make(map[string]int)
 	
 	for i, doc := range documents {
-		// Объединяем заголовок и текст (заголовок важнее - дублируем)
-		combinedText := doc.Title + " " + doc.Title + " " + doc.Text
-		tokens := tokenize(combinedText)
-		tokenizedDocs[i] = tokens
+		// Объединяем заголовок и текст (заголовок важнее - дублируем),
+		// "хлебные крошки" и описание страницы тоже описывают ее тему
+		combinedText := doc.Title + " " + doc.Title + " " + strings.Join(doc.Breadcrumbs, " ") + " " + strings.Join(doc.Section, " ") + " " + doc.Description + " " + doc.Text
+		tokens := tf.Analyzer.Analyze(combinedText)
 		tf.DocLengths[i] = len(tokens)
-	}
-	
-	// Подсчитываем частоту термов в каждом документе
-	tf.DocFreqs = make([]map[string]int, tf.NumDocs)
-	for i, tokens := range tokenizedDocs {
+
 		freq := make(map[string]int)
 		for _, token := range tokens {
 			freq[token]++
 		}
-		tf.DocFreqs[i] = freq
-	}
-	
-	// Подсчитываем document frequency для каждого терма
-	df := make(map[string]int)
-	for _, docFreq := range tf.DocFreqs {
-		for term := range docFreq {
+		for term := range freq {
 			df[term]++
 		}
+		docFreqs[i] = freq
 	}
 	
-	// Вычисляем IDF (классическая формула)
-	for term, freq := range df {
-		tf.IDF[term] = math.Log(float64(tf.NumDocs) / float64(freq))
-	}
-	
-	log.Printf("Индекс построен: %d документов, %d уникальных термов", tf.NumDocs, len(tf.IDF))
-}
-
-// score вычисляет TF-IDF score для документа
-func (tfidf *TFIDF) score(queryTokens []string, docIdx int) float64 {
-	score := 0.0
-	docLen := float64(tfidf.DocLengths[docIdx])
-	docFreq := tfidf.DocFreqs[docIdx]
-	
-	for _, term := range queryTokens {
-		freq := float64(docFreq[term])
-		if freq == 0 {
-			continue
+	// В индекс кладем готовый вклад терма: TF с нормализацией по длине документа,
+	// умноженный на IDF (классическая формула)
+	for i, freq := range docFreqs {
+		for term, n := range freq {
+			idf := math.Log(float64(tf.NumDocs) / float64(df[term]))
+			tf.index.add(term, i, float64(n)/float64(tf.DocLengths[i])*idf)
 		}
-		
-		// TF (term frequency) с нормализацией по длине документа
-		termFreq := freq / docLen
-		
-		// IDF (inverse document frequency)
-		idf := tfidf.IDF[term]
-		
-		// TF-IDF
-		score += termFreq * idf
 	}
+	tf.index.compact()
 	
-	return score
+	log.Printf("Индекс построен: %d документов, %d уникальных термов", tf.NumDocs, len(df))
 }
 
 // Search ищет наиболее релевантные документы
 func (tf *TFIDF) Search(query string, topK int) []SearchResult {
-	queryTokens := tokenize(query)
-	
-	// Вычисляем score для всех документов и сразу сохраняем результаты
-	var results []SearchResult
-	for i := 0; i < tf.NumDocs; i++ {
-		score := tf.score(queryTokens, i)
-		if score > 0 {
-			results = append(results, SearchResult{
-				Document: tf.Documents[i],
-				Score:    score,
-			})
-		}
-	}
-	
-	// Сортируем по убыванию score
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Score > results[j].Score
-	})
-	
-	// Возвращаем только topK результатов
-	if len(results) > topK {
-		return results[:topK]
+	return searchResults(tf.Documents, tf.index.search(tf.Analyzer.Analyze(query), topK))
+}
+
+// searchResults превращает найденные номера документов в результаты поиска
+func searchResults(documents []Document, top []scoredDoc) []SearchResult {
+	results := make([]SearchResult, len(top))
+	for i, d := range top {
+		results[i] = SearchResult{Document: documents[d.doc], Score: d.score}
 	}
 	return results
 }
 
-// KnowledgeBase база знаний с поиском
+// KnowledgeBase база знаний с поиском.
+// Родительские разделы не индексируются: в контекст они попадают
+// вместо найденных в них чанков.
 type KnowledgeBase struct {
-	SearchEngine *TFIDF
+	// SearchEngine ранжирование чанков: TF-IDF по умолчанию или BM25F (см. NewRanker)
+	SearchEngine Ranker
 	Chunks       []Document
+	Parents      map[int]Document
 }
 
 // NewKnowledgeBase создает новую базу знаний
@@ -161,6 +148,7 @@ func NewKnowledgeBase() *KnowledgeBase {
 	return &KnowledgeBase{
 		SearchEngine: NewTFIDF(),
 		Chunks:       []Document{},
+		Parents:      make(map[int]Document),
 	}
 }
 
@@ -173,12 +161,23 @@ func (kb *KnowledgeBase) LoadChunks(filename string) error {
 		return fmt.Errorf("ошибка чтения файла: %w", err)
 	}
 	
-	err = json.Unmarshal(data, &kb.Chunks)
+	var docs []Document
+	err = json.Unmarshal(data, &docs)
 	if err != nil {
 		return fmt.Errorf("ошибка парсинга JSON: %w", err)
 	}
+
+	kb.Chunks = kb.Chunks[:0]
+	kb.Parents = make(map[int]Document)
+	for _, doc := range docs {
+		if doc.Kind == KindSection {
+			kb.Parents[doc.ID] = doc
+		} else {
+			kb.Chunks = append(kb.Chunks, doc)
+		}
+	}
 	
-	log.Printf("Загружено %d чанков", len(kb.Chunks))
+	log.Printf("Загружено %d чанков, %d родительских разделов", len(kb.Chunks), len(kb.Parents))
 	
 	// Строим индекс
 	kb.SearchEngine.BuildIndex(kb.Chunks)
@@ -191,9 +190,12 @@ func (kb *KnowledgeBase) Search(query string, topK int) []SearchResult {
 	return kb.SearchEngine.Search(query, topK)
 }
 
-// GetContextForQuery получает контекст для добавления в промпт
-func (kb *KnowledgeBase) GetContextForQuery(query string, maxChunks int) string {
-	results := kb.Search(query, maxChunks)
+// GetContextForQuery собирает контекст для промпта в пределах maxTokens
+// токенов GigaChat. Источники добавляются по убыванию релевантности, пока
+// помещаются; последний обрезается по границе предложения. Для чанка
+// с родительским разделом в контекст идет весь раздел, каждый раздел — один раз.
+func (kb *KnowledgeBase) GetContextForQuery(query string, maxTokens int) string {
+	results := kb.Search(query, contextCandidates)
 	
 	if len(results) == 0 {
 		return ""
@@ -201,12 +203,58 @@ func (kb *KnowledgeBase) GetContextForQuery(query string, maxChunks int) string
 	
 	// Собираем контекст
 	context := "Релевантная информация из базы знаний:\n\n"
+	budget := maxTokens - tokens.Estimate(context)
 	
-	for i, result := range results {
-		context += fmt.Sprintf("--- Источник %d: %s ---\n", i+1, result.Document.Title)
-		context += result.Document.Text
-		context += fmt.Sprintf("\n(URL: %s)\n\n", result.Document.URL)
+	sent := make(map[int]bool)
+	n := 0
+	for _, result := range results {
+		doc := result.Document
+		if doc.ParentID != nil {
+			if parent, ok := kb.Parents[*doc.ParentID]; ok {
+				if sent[parent.ID] {
+					continue
+				}
+				sent[parent.ID] = true
+				doc = parent
+			}
+		}
+
+		title := doc.Title
+		if len(doc.Breadcrumbs) > 0 {
+			title = strings.Join(doc.Breadcrumbs, " > ")
+		}
+		if len(doc.Section) > 0 {
+			title += " > " + strings.Join(doc.Section, " > ")
+		}
+		head := fmt.Sprintf("--- Источник %d: %s ---\n", n+1, title)
+		footer := fmt.Sprintf("\n(URL: %s)\n\n", doc.URL)
+		if doc.LastModified != "" {
+			footer = fmt.Sprintf("\n(URL: %s, обновлено: %s)\n\n", doc.URL, doc.LastModified)
+		}
+
+		// Источник, который не помещается целиком, обрезаем и на нем останавливаемся
+		cost := tokens.Estimate(head) + tokens.Estimate(footer)
+		text := doc.Text
+		full := cost+tokens.Estimate(text) <= budget
+		if !full {
+			if budget-cost < minSourceTokens {
+				break
+			}
+			if text = tokens.Truncate(text, budget-cost); text == "" {
+				break
+			}
+		}
+
+		n++
+		context += head + text + footer
+		budget -= cost + tokens.Estimate(text)
+		if !full {
+			break
+		}
 	}
 	
+	if n == 0 {
+		return ""
+	}
 	return context
 }
@@ -0,0 +1,86 @@
+package tokens
+
+import (
+	"strings"
+	"unicode"
+)
+
+// abbreviations сокращения, после точки в которых предложение не заканчивается
+// (сравниваются в нижнем регистре без последней точки)
+var abbreviations = map[string]bool{
+	"т.е": true, "т.д": true, "т.п": true, "т.к": true, "т.ч": true, "т.н": true, "и.о": true,
+	"г": true, "гг": true, "в": true, "вв": true, "ул": true, "д": true, "стр": true, "корп": true,
+	"пр": true, "просп": true, "пер": true, "пл": true, "наб": true, "ш": true, "обл": true, "р-н": true,
+	"им": true, "см": true, "ср": true, "напр": true, "др": true, "мин": true, "макс": true,
+	"руб": true, "коп": true, "тыс": true, "млн": true, "млрд": true, "ч": true, "час": true,
+	"проф": true, "акад": true, "доц": true, "канд": true, "каф": true, "зав": true, "ген": true,
+	"тел": true, "доб": true, "моб": true, "рис": true, "табл": true, "гл": true, "п": true, "пп": true,
+	"ст": true, "ред": true, "изд": true, "англ": true, "лат": true, "etc": true, "e.g": true, "i.e": true,
+}
+
+// SentenceStarts возвращает позиции начала предложений и абзацев (в рунах).
+// Граница — перевод строки или знак конца предложения, за которым после
+// пробелов идет заглавная буква, цифра, кавычка или тире; точка после
+// сокращения ("т.е.", "г.") или инициала ("А. С. Пушкин") границей не считается.
+func SentenceStarts(runes []rune) []int {
+	starts := []int{0}
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '\n' {
+			if j := skipSpaces(runes, i+1); j < len(runes) {
+				starts = append(starts, j)
+				i = j - 1
+			}
+			continue
+		}
+		if r != '.' && r != '!' && r != '?' && r != '…' {
+			continue
+		}
+
+		// Многоточие и "?!" считаем одним знаком
+		end := i
+		for end+1 < len(runes) && strings.ContainsRune(".!?…", runes[end+1]) {
+			end++
+		}
+		j := skipSpaces(runes, end+1)
+		if j == end+1 || j >= len(runes) || !opensSentence(runes[j]) {
+			i = end
+			continue
+		}
+		if r == '.' && end == i && isAbbreviation(runes, i) {
+			i = end
+			continue
+		}
+		starts = append(starts, j)
+		i = j - 1
+	}
+	return starts
+}
+
+// skipSpaces возвращает позицию первого непробельного символа начиная с i
+func skipSpaces(runes []rune, i int) int {
+	for i < len(runes) && unicode.IsSpace(runes[i]) {
+		i++
+	}
+	return i
+}
+
+// opensSentence проверяет, может ли символ начинать предложение
+func opensSentence(r rune) bool {
+	return unicode.IsUpper(r) || unicode.IsDigit(r) || strings.ContainsRune("«\"'(—–-", r)
+}
+
+// isAbbreviation проверяет, что точка в позиции dot завершает сокращение или инициал
+func isAbbreviation(runes []rune, dot int) bool {
+	start := dot
+	for start > 0 && !unicode.IsSpace(runes[start-1]) && runes[start-1] != '(' && runes[start-1] != '«' {
+		start--
+	}
+	word := strings.ToLower(string(runes[start:dot]))
+	if abbreviations[word] {
+		return true
+	}
+	// Инициал: одна заглавная буква
+	letters := []rune(word)
+	return len(letters) == 1 && unicode.IsLetter(letters[0]) && unicode.IsUpper(runes[start])
+}
@@ -0,0 +1,52 @@
+package tokens
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// sentences делит текст по найденным границам предложений
+func sentences(text string) []string {
+	runes := []rune(text)
+	starts := SentenceStarts(runes)
+	var parts []string
+	for i, start := range starts {
+		end := len(runes)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		parts = append(parts, strings.TrimSpace(string(runes[start:end])))
+	}
+	return parts
+}
+
+func TestSentenceStarts(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"простые", "Первое предложение. Второе предложение.",
+			[]string{"Первое предложение.", "Второе предложение."}},
+		{"т.е.", "Курс для взрослых, т.е. Старше восемнадцати лет. Запись открыта.",
+			[]string{"Курс для взрослых, т.е. Старше восемнадцати лет.", "Запись открыта."}},
+		{"г. и ул.", "Адрес: г. Москва, ул. Тверская, д. 7. Звоните нам.",
+			[]string{"Адрес: г. Москва, ул. Тверская, д. 7.", "Звоните нам."}},
+		{"инициалы", "Ведет занятия А. С. Петров. Запись по телефону.",
+			[]string{"Ведет занятия А. С. Петров.", "Запись по телефону."}},
+		{"знаки", "Что? Где?! Когда… Всегда.",
+			[]string{"Что?", "Где?!", "Когда…", "Всегда."}},
+		{"строки", "Первая строка\nвторая строка",
+			[]string{"Первая строка", "вторая строка"}},
+		{"без пробела", "Версия 2.0 вышла. Строчная. буква",
+			[]string{"Версия 2.0 вышла.", "Строчная. буква"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sentences(tt.text); !slices.Equal(got, tt.want) {
+				t.Errorf("предложения %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
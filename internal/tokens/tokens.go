@@ -0,0 +1,93 @@
+// Package tokens приблизительно оценивает размер текста в токенах GigaChat.
+// Точный токенизатор модели недоступен, поэтому оценка строится по словам:
+// русское слово в среднем занимает токен на 3-4 буквы, знак препинания — отдельный токен.
+package tokens
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Число символов на токен для букв и цифр
+const (
+	lettersPerToken = 4
+	digitsPerToken  = 3
+)
+
+// MaxRunes сколько символов заведомо помещается в n токенов
+func MaxRunes(n int) int {
+	return n * digitsPerToken
+}
+
+// Estimate возвращает приблизительное число токенов в тексте
+func Estimate(text string) int {
+	count := 0
+	letters, digits := 0, 0
+	flush := func() {
+		count += (letters+lettersPerToken-1)/lettersPerToken + (digits+digitsPerToken-1)/digitsPerToken
+		letters, digits = 0, 0
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsMark(r):
+			if digits > 0 {
+				flush()
+			}
+			letters++
+		case unicode.IsDigit(r):
+			if letters > 0 {
+				flush()
+			}
+			digits++
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			flush()
+			count++
+		}
+	}
+	flush()
+	return count
+}
+
+// Truncate обрезает текст до n токенов по границе предложения или абзаца
+// (границы те же, что у SentenceStarts). Если даже первое предложение
+// не помещается, текст режется по словам. Оценка префикса накапливается
+// по словам за один проход: пробелы не входят в оценку ни одного слова.
+func Truncate(text string, n int) string {
+	if Estimate(text) <= n {
+		return text
+	}
+
+	runes := []rune(text)
+	starts := SentenceStarts(runes)
+	next := 1
+
+	total, sentence, word := 0, 0, 0
+	for i := skipSpaces(runes, 0); i < len(runes); {
+		end := i
+		for end < len(runes) && !unicode.IsSpace(runes[end]) {
+			end++
+		}
+		total += Estimate(string(runes[i:end]))
+		if total > n {
+			break
+		}
+		word = end
+
+		// Следующее слово начинает предложение: здесь можно обрезать по предложению
+		i = skipSpaces(runes, end)
+		for next < len(starts) && starts[next] <= i {
+			if starts[next] == i {
+				sentence = end
+			}
+			next++
+		}
+	}
+
+	if sentence == 0 {
+		sentence = word
+	}
+	return strings.TrimSpace(string(runes[:sentence]))
+}
@@ -0,0 +1,72 @@
+package tokens
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"курс", 1},
+		{"программа", 3},
+		{"2024", 2},
+		{"курс, 72 часа.", 5},
+		{"  много   пробелов  ", 4},
+	}
+	for _, tt := range tests {
+		if got := Estimate(tt.text); got != tt.want {
+			t.Errorf("Estimate(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	text := "Курс проходит в г. Москва, т.е. в центре города. Занятия по субботам. Запись открыта."
+	tests := []struct {
+		name string
+		text string
+		n    int
+		want string
+	}{
+		{"помещается", text, 100, text},
+		{"по предложению", text, Estimate("Курс проходит в г. Москва, т.е. в центре города. Занятия по субботам."),
+			"Курс проходит в г. Москва, т.е. в центре города. Занятия по субботам."},
+		// Точка после сокращения не граница: обрезаем по целому первому предложению
+		{"сокращения", text, Estimate("Курс проходит в г. Москва, т.е. в центре города.") + 1,
+			"Курс проходит в г. Москва, т.е. в центре города."},
+		{"по словам", text, Estimate("Курс проходит в"), "Курс проходит в"},
+		{"абзац", "Первый абзац без точки\nвторой абзац", Estimate("Первый абзац без точки") + 1, "Первый абзац без точки"},
+		{"ничего", "Переподготовка", 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Truncate(tt.text, tt.n); got != tt.want {
+				t.Errorf("Truncate(%d) = %q, want %q", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateWithinBudget(t *testing.T) {
+	text := strings.Repeat("Учебный центр проводит курсы для специалистов, т.е. для взрослых слушателей. ", 50)
+	for n := 1; n < Estimate(text); n += 7 {
+		got := Truncate(text, n)
+		if Estimate(got) > n {
+			t.Fatalf("Truncate(%d): %d токенов", n, Estimate(got))
+		}
+		if n >= Estimate("Учебный центр проводит курсы для специалистов, т.е. для взрослых слушателей.") && !strings.HasSuffix(got, "слушателей.") {
+			t.Fatalf("Truncate(%d) обрезал не по предложению: ...%q", n, got[max(0, len(got)-40):])
+		}
+	}
+}
+
+func BenchmarkTruncate(b *testing.B) {
+	text := strings.Repeat("Учебный центр проводит курсы для специалистов, т.е. для взрослых слушателей. ", 2000)
+	for i := 0; i < b.N; i++ {
+		Truncate(text, 10000)
+	}
+}